@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ArchiveSeparator separates the archive name from the path of a file in the archive, e.g. "dist.zip!/LICENSE"
+const ArchiveSeparator = "!/"
+
+// The limits of the decompressed size of the files in an archive (see ArchiveLimits)
+const (
+	MaxArchiveFileBytes = 64 << 20
+	MaxArchiveBytes     = 256 << 20
+)
+
+// ErrArchiveTooLarge is returned when a file in an archive, or all its files, decompress to more than the ArchiveLimits
+var ErrArchiveTooLarge = errors.New("archive too large")
+
+// ArchiveLimits limit the decompressed size of the files in an archive, so a zip or tar bomb cannot exhaust the memory
+type ArchiveLimits struct {
+	// FileBytes is the largest size of each file
+	FileBytes int64
+	// TotalBytes is the largest total size of the files
+	TotalBytes int64
+}
+
+// DefaultArchiveLimits returns the limits used by WalkArchive and ArchiveSpecs
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{FileBytes: MaxArchiveFileBytes, TotalBytes: MaxArchiveBytes}
+}
+
+// archiveReader reads the files of an archive within the limits
+type archiveReader struct {
+	limits ArchiveLimits
+	total  int64
+}
+
+// readFile reads a file of the archive, counting its size towards the total size of the files
+func (a *archiveReader) readFile(filePath string, r io.Reader) ([]byte, error) {
+	limit := a.limits.FileBytes
+	if remaining := a.limits.TotalBytes - a.total; remaining < limit {
+		limit = remaining
+	}
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > a.limits.FileBytes {
+		return nil, fmt.Errorf("%w: %v is larger than %v bytes", ErrArchiveTooLarge, filePath, a.limits.FileBytes)
+	}
+	a.total += int64(len(b))
+	if a.total > a.limits.TotalBytes {
+		return nil, fmt.Errorf("%w: the files are larger than %v bytes in total", ErrArchiveTooLarge, a.limits.TotalBytes)
+	}
+	return b, nil
+}
+
+// IsArchive reports whether the file name has a supported archive extension (zip, jar, tar, tar.gz or tgz)
+func IsArchive(name string) bool {
+	return archiveFormat(name) != ""
+}
+
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	default:
+		return ""
+	}
+}
+
+// WalkArchive calls fn with the path and contents of each regular file in the archive.
+// The archive format is determined by the name. The files are limited by the DefaultArchiveLimits.
+func WalkArchive(name string, data []byte, fn func(filePath string, contents []byte) error) error {
+	return WalkArchiveWithLimits(name, data, DefaultArchiveLimits(), fn)
+}
+
+// WalkArchiveWithLimits is WalkArchive with limits of the decompressed size of the files.
+// An error wrapping ErrArchiveTooLarge is returned when a limit is exceeded.
+func WalkArchiveWithLimits(name string, data []byte, limits ArchiveLimits, fn func(filePath string, contents []byte) error) error {
+	switch archiveFormat(name) {
+	case "zip":
+		return walkZip(data, &archiveReader{limits: limits}, fn)
+	case "tgz":
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("read archive %v error: %w", name, err)
+		}
+		defer gz.Close()
+		return walkTar(gz, &archiveReader{limits: limits}, fn)
+	case "tar":
+		return walkTar(bytes.NewReader(data), &archiveReader{limits: limits}, fn)
+	default:
+		return fmt.Errorf("unsupported archive type: %v", name)
+	}
+}
+
+func walkZip(data []byte, ar *archiveReader, fn func(filePath string, contents []byte) error) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		b, err := ar.readFile(f.Name, rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := fn(f.Name, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(r io.Reader, ar *archiveReader, fn func(filePath string, contents []byte) error) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := ar.readFile(hdr.Name, tr)
+		if err != nil {
+			return err
+		}
+		if err := fn(hdr.Name, b); err != nil {
+			return err
+		}
+	}
+}
+
+// ArchiveSpecs returns a ScanSpec for each regular file in the archive.
+// Each spec is named with the archive name and the file path, e.g. "dist.zip!/LICENSE".
+func ArchiveSpecs(name string, data []byte) ([]ScanSpec, error) {
+	var specs []ScanSpec
+	err := WalkArchive(name, data, func(filePath string, contents []byte) error {
+		specs = append(specs, ScanSpec{
+			Name:        name + ArchiveSeparator + filePath,
+			LicenseText: string(contents),
+		})
+		return nil
+	})
+	return specs, err
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package scanner_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/api/scanner"
+)
+
+func TestArchiveSpecs(t *testing.T) {
+	t.Parallel()
+
+	files := []struct{ name, text string }{
+		{"pkg/LICENSE", "license text"},
+		{"pkg/NOTICE", "notice text"},
+	}
+
+	var zipBytes bytes.Buffer
+	zw := zip.NewWriter(&zipBytes)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(f.text))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tgzBytes bytes.Buffer
+	gw := gzip.NewWriter(&tgzBytes)
+	tw := tar.NewWriter(gw)
+	_ = tw.WriteHeader(&tar.Header{Name: "pkg/", Typeflag: tar.TypeDir, Mode: 0o755})
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(f.text))}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(f.text))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "dist.zip", data: zipBytes.Bytes()},
+		{name: "dist.tar.gz", data: tgzBytes.Bytes()},
+		{name: "dist.tgz", data: []byte("not gzip"), wantErr: true},
+		{name: "dist.rar", data: zipBytes.Bytes(), wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := scanner.ArchiveSpecs(tt.name, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ArchiveSpecs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := []scanner.ScanSpec{
+				{Name: tt.name + "!/pkg/LICENSE", LicenseText: "license text"},
+				{Name: tt.name + "!/pkg/NOTICE", LicenseText: "notice text"},
+			}
+			if d := cmp.Diff(want, got); d != "" {
+				t.Errorf("Didn't get expected specs: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestWalkArchiveWithLimits(t *testing.T) {
+	t.Parallel()
+
+	var tarBytes bytes.Buffer
+	tw := tar.NewWriter(&tarBytes)
+	for _, name := range []string{"pkg/LICENSE", "pkg/NOTICE"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: 8}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte("8 bytes!"))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		limits  scanner.ArchiveLimits
+		wantErr error
+	}{
+		{name: "within the limits", limits: scanner.ArchiveLimits{FileBytes: 8, TotalBytes: 16}},
+		{name: "file too large", limits: scanner.ArchiveLimits{FileBytes: 7, TotalBytes: 16}, wantErr: scanner.ErrArchiveTooLarge},
+		{name: "files too large", limits: scanner.ArchiveLimits{FileBytes: 8, TotalBytes: 12}, wantErr: scanner.ErrArchiveTooLarge},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := scanner.WalkArchiveWithLimits("dist.tar", tarBytes.Bytes(), tt.limits, func(string, []byte) error { return nil })
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WalkArchiveWithLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
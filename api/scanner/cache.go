@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// resultsCacheVersion must be incremented whenever identification or the ScanResult JSON changes,
+// so that persisted results from an older version are not used
+const resultsCacheVersion = 1
+
+// ResultsCache holds scan results by the digest of the normalized license text.
+// A cache is used with one license library, unless it is keyed by the library fingerprint (see NewDirResultsCache).
+type ResultsCache interface {
+	Get(digest normalizer.Digest) (*ScanResult, bool)
+	Put(digest normalizer.Digest, result *ScanResult)
+}
+
+// mapResultsCache is a ResultsCache for use by one goroutine
+type mapResultsCache map[normalizer.Digest]*ScanResult
+
+func (c mapResultsCache) Get(digest normalizer.Digest) (*ScanResult, bool) {
+	r, ok := c[digest]
+	return r, ok
+}
+
+func (c mapResultsCache) Put(digest normalizer.Digest, result *ScanResult) {
+	c[digest] = result
+}
+
+// syncResultsCache is a ResultsCache which is safe for concurrent use
+type syncResultsCache struct {
+	mu      sync.RWMutex
+	results map[normalizer.Digest]*ScanResult
+}
+
+// NewSyncResultsCache returns an in-memory ResultsCache which is safe for concurrent use
+func NewSyncResultsCache() ResultsCache {
+	return &syncResultsCache{results: make(map[normalizer.Digest]*ScanResult)}
+}
+
+func (c *syncResultsCache) Get(digest normalizer.Digest) (*ScanResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.results[digest]
+	return r, ok
+}
+
+func (c *syncResultsCache) Put(digest normalizer.Digest, result *ScanResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[digest] = result
+}
+
+// lruResultsCache is an in-memory ResultsCache which keeps the most recently used results
+type lruResultsCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[normalizer.Digest]*list.Element
+}
+
+type lruEntry struct {
+	digest normalizer.Digest
+	result *ScanResult
+}
+
+// NewLRUResultsCache returns an in-memory ResultsCache, safe for concurrent use, which keeps up to size results
+// (evicting the least recently used)
+func NewLRUResultsCache(size int) ResultsCache {
+	if size < 1 {
+		size = 1
+	}
+	return &lruResultsCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[normalizer.Digest]*list.Element),
+	}
+}
+
+func (c *lruResultsCache) Get(digest normalizer.Digest) (*ScanResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[digest]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).result, true
+}
+
+func (c *lruResultsCache) Put(digest normalizer.Digest, result *ScanResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[digest]; ok {
+		e.Value.(*lruEntry).result = result
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[digest] = c.order.PushFront(&lruEntry{digest: digest, result: result})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).digest)
+	}
+}
+
+// dirResultsCache is a ResultsCache persisted as a JSON file per result in a directory
+type dirResultsCache struct {
+	dir string
+}
+
+// NewDirResultsCache returns a persistent ResultsCache which stores each result as a JSON file under dir.
+// Results are keyed by the SHA-256 of the normalized text and by the license library fingerprint
+// (see licenses.LicenseLibrary.Fingerprint), so results are not reused after the license templates change.
+// The cache is safe for concurrent use, including by several processes.
+func NewDirResultsCache(dir string, fingerprint string) (ResultsCache, error) {
+	if dir == "" || fingerprint == "" {
+		return nil, fmt.Errorf("a results cache needs a directory and a library fingerprint")
+	}
+	d := filepath.Join(dir, fmt.Sprintf("v%v-%v", resultsCacheVersion, fingerprint))
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create results cache %v: %w", d, err)
+	}
+	return &dirResultsCache{dir: d}, nil
+}
+
+func (c *dirResultsCache) file(digest normalizer.Digest) (string, bool) {
+	if len(digest.Sha256) < 2 {
+		return "", false
+	}
+	return filepath.Join(c.dir, digest.Sha256[:2], digest.Sha256+".json"), true
+}
+
+func (c *dirResultsCache) Get(digest normalizer.Digest) (*ScanResult, bool) {
+	f, ok := c.file(digest)
+	if !ok {
+		return nil, false
+	}
+	b, err := os.ReadFile(f)
+	if err != nil {
+		return nil, false
+	}
+	var r ScanResult
+	if err := json.Unmarshal(b, &r); err != nil {
+		Logger.Debugf("Ignoring invalid results cache file %v: %v", f, err)
+		return nil, false
+	}
+	return &r, true
+}
+
+func (c *dirResultsCache) Put(digest normalizer.Digest, result *ScanResult) {
+	f, ok := c.file(digest)
+	if !ok || result.Error != nil {
+		return
+	}
+	// The spec and original text are from one scan and are replaced when the result is used
+	r := *result
+	r.Spec = ScanSpec{}
+	r.OriginalText = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		_ = Logger.Errorf("cannot marshal result for the results cache: %v", err)
+		return
+	}
+	if err := writeFileAtomic(f, b); err != nil {
+		_ = Logger.Errorf("cannot write results cache file %v: %v", f, err)
+	}
+}
+
+// writeFileAtomic writes the file with a rename, so a concurrent reader never reads a partial file
+func writeFileAtomic(name string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
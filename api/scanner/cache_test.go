@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package scanner_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+func TestLRUResultsCache(t *testing.T) {
+	t.Parallel()
+
+	digest := func(s string) normalizer.Digest { return normalizer.Digest{Sha256: s} }
+	result := func(s string) *scanner.ScanResult { return &scanner.ScanResult{NormalizedText: s} }
+
+	c := scanner.NewLRUResultsCache(2)
+	c.Put(digest("a"), result("a"))
+	c.Put(digest("b"), result("b"))
+	if _, ok := c.Get(digest("a")); !ok { // a is now more recent than b
+		t.Fatal("expected a in the cache")
+	}
+	c.Put(digest("c"), result("c")) // evicts b
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		got, ok := c.Get(digest(key))
+		if ok != want {
+			t.Errorf("Get(%v) ok = %v, want %v", key, ok, want)
+		}
+		if ok && got.NormalizedText != key {
+			t.Errorf("Get(%v) = %v", key, got.NormalizedText)
+		}
+	}
+}
+
+func TestDirResultsCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	digest := normalizer.Digest{Md5: "md5", Sha256: "0123456789abcdef", Sha512: "sha512"}
+	result := &scanner.ScanResult{
+		Spec:           scanner.ScanSpec{Name: "LICENSE"},
+		OriginalText:   "original",
+		NormalizedText: "normalized",
+		Hash:           &digest,
+		CycloneDXLicenses: scanner.Licenses{{
+			License: &scanner.License{ID: "MIT", Name: "MIT License (MIT)", Text: &scanner.AttachedText{}},
+		}},
+	}
+
+	c, err := scanner.NewDirResultsCache(dir, "fingerprint1")
+	if err != nil {
+		t.Fatalf("NewDirResultsCache() error = %v", err)
+	}
+	if _, ok := c.Get(digest); ok {
+		t.Fatal("expected an empty cache")
+	}
+	c.Put(digest, result)
+
+	// A new cache for the same directory and fingerprint reads the result
+	reopened, err := scanner.NewDirResultsCache(dir, "fingerprint1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reopened.Get(digest)
+	if !ok {
+		t.Fatal("expected the result to be persisted")
+	}
+	want := *result
+	want.Spec = scanner.ScanSpec{} // the spec and original text are not persisted
+	want.OriginalText = ""
+	if d := cmp.Diff(&want, got); d != "" {
+		t.Errorf("Didn't get expected result: (-want, +got): %v", d)
+	}
+
+	// A different library fingerprint does not use the result
+	changed, err := scanner.NewDirResultsCache(dir, "fingerprint2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := changed.Get(digest); ok {
+		t.Error("expected no result for a different fingerprint")
+	}
+
+	if _, err := scanner.NewDirResultsCache(dir, ""); err == nil {
+		t.Error("expected an error without a fingerprint")
+	}
+}
+
+func TestScanner_ResultsCacheFlag(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	flagSet := configurer.NewDefaultFlags()
+	if err := flagSet.Set(configurer.ResultsCacheFlag, dir); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := scanner.New(scanner.Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want := first.ScanText("LICENSE", mitText)
+	if d := cmp.Diff([]string{"MIT"}, licenseIDs(want)); d != "" {
+		t.Fatalf("Didn't get expected license IDs: (-want, +got): %v", d)
+	}
+
+	var files []string
+	_ = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if len(files) != 1 {
+		t.Fatalf("expected 1 cached result, got %v", files)
+	}
+
+	// A new scanner (e.g. the next run) uses the persisted result
+	second, err := scanner.New(scanner.Options{Config: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := second.ScanText("COPYING", mitText)
+	if got.Spec.Name != "COPYING" || got.OriginalText != mitText {
+		t.Errorf("expected the spec and text of this scan, got %v", got.Spec)
+	}
+	if d := cmp.Diff(want.CycloneDXLicenses, got.CycloneDXLicenses); d != "" {
+		t.Errorf("Didn't get expected cached licenses: (-want, +got): %v", d)
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrFileNotInArchive is returned when the file path in an archive location is not in the archive
+var ErrFileNotInArchive = errors.New("file not found in archive")
+
+// ReadLocation reads the file at a location, which is one of:
+//
+//	a local file path, e.g. "node_modules/async/LICENSE"
+//	a file URL, e.g. "file:///src/async/LICENSE"
+//	a file in an archive (zip, jar, tar, tar.gz or tgz), e.g. "dist.tgz!/package/LICENSE" or "file:///dist.tgz!/package/LICENSE"
+func ReadLocation(location string) ([]byte, error) {
+	archiveLocation, filePath, inArchive := strings.Cut(location, ArchiveSeparator)
+
+	localPath, err := localPath(archiveLocation)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(localPath)
+	if err != nil || !inArchive {
+		return b, err
+	}
+
+	// Find the file in the archive. Paths are compared without a leading "./" or "/".
+	want := cleanArchivePath(filePath)
+	var contents []byte
+	found := false
+	err = WalkArchive(localPath, b, func(name string, fileContents []byte) error {
+		if cleanArchivePath(name) == want {
+			contents = fileContents
+			found = true
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %v", ErrFileNotInArchive, location)
+	}
+	return contents, nil
+}
+
+var errStopWalk = errors.New("stop walk")
+
+// localPath returns the file path for a local path or a file URL
+func localPath(location string) (string, error) {
+	if !strings.Contains(location, "://") {
+		return location, nil
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid location %v: %w", location, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported location %v: only local paths and file URLs can be read", location)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("unsupported location %v: file URLs must be on localhost", location)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+func cleanArchivePath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package scanner_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBM/license-scanner/api/scanner"
+)
+
+func TestReadLocation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	licensePath := filepath.Join(dir, "LICENSE")
+	if err := os.WriteFile(licensePath, []byte("license text"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var zipBytes bytes.Buffer
+	zw := zip.NewWriter(&zipBytes)
+	w, err := zw.Create("./pkg/LICENSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("archived license text"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	jarPath := filepath.Join(dir, "dist.jar")
+	if err := os.WriteFile(jarPath, zipBytes.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fileURL := func(p string) string { return "file://" + filepath.ToSlash(p) }
+
+	tests := []struct {
+		name     string
+		location string
+		want     string
+		wantErr  bool
+		errIs    error
+	}{
+		{name: "local path", location: licensePath, want: "license text"},
+		{name: "file URL", location: fileURL(licensePath), want: "license text"},
+		{name: "archive path", location: jarPath + scanner.ArchiveSeparator + "pkg/LICENSE", want: "archived license text"},
+		{name: "archive file URL", location: fileURL(jarPath) + scanner.ArchiveSeparator + "/pkg/LICENSE", want: "archived license text"},
+		{name: "missing file", location: filepath.Join(dir, "missing"), wantErr: true, errIs: os.ErrNotExist},
+		{name: "missing in archive", location: jarPath + scanner.ArchiveSeparator + "LICENSE", wantErr: true, errIs: scanner.ErrFileNotInArchive},
+		{name: "not an archive", location: licensePath + scanner.ArchiveSeparator + "LICENSE", wantErr: true},
+		{name: "remote URL", location: "https://github.com/caolan/async/", wantErr: true},
+		{name: "remote file URL", location: "file://example.com/LICENSE", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := scanner.ReadLocation(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadLocation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("ReadLocation() error = %v, want %v", err, tt.errIs)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadLocation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,12 @@
 package scanner
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/mrutkows/sbom-utility/log"
 	"github.com/spf13/pflag"
 
 	"github.com/IBM/license-scanner/configurer"
@@ -18,6 +21,8 @@ import (
 // NOASSERTION_SPDX_NAME in License SPDX Name signify that the license text passed through the scan without any errors but no match was found
 const NOASSERTION_SPDX_NAME = "NOASSERTION"
 
+var Logger = log.NewLogger(log.INFO)
+
 // ScanSpecs holds the package manager, the programming language, and a list of multiple packages with their specifications
 type ScanSpecs struct {
 	// package manager to search for
@@ -41,14 +46,13 @@ type ScanSpec struct {
 	// If no version is provided, the scanning service defaults to the package manager default which is mostly the latest version.
 	Version string
 	// location from where the file can be retrieved or a package can be downloaded.
-	// If no location is provided, the package source location is retrieved from the package manager.
-	// TODO: Resolve - Can we get the file content from the file system or should that be included as part of the specification?
+	// ScanFile reads the file from a local path, a file:// URL, or a path in an archive, e.g. "dist.tgz!/package/LICENSE" (see ReadLocation).
 	Location string
 	// Package URL to search for.
 	// This is the standardized URL used to identify and locate a software package across many programming languages and package managers.
 	PURL string
 	// file hash or package hash to search for.
-	// This will also be matched against known file hashes.
+	// Without a license text, the SHA-256 is matched against the known hashes of normalized license texts (see licenses.KnownHashes).
 	// TODO: Create a proposal for hashing algorithm of a package.
 	Hash *normalizer.Digest
 	// license input text to match and identify the license against the data set
@@ -93,6 +97,8 @@ type ScanResult struct {
 	OriginalText string
 	// normalized version of the source text which is compared against the license text
 	NormalizedText string
+	// the detected encoding of the file read from the Location (see normalizer.DecodeText), empty for a LicenseText
+	Encoding string
 	// file hash or package hash
 	// set to the hash if provided or calculate based on the input text (normalized)
 	Hash *normalizer.Digest
@@ -102,45 +108,68 @@ type ScanResult struct {
 	CycloneDXLicenses Licenses
 }
 
+// MarshalJSON marshals the ScanResult with the error as a string (an error interface would marshal as {})
+func (r ScanResult) MarshalJSON() ([]byte, error) {
+	type scanResult ScanResult // without the MarshalJSON method
+	var errString string
+	if r.Error != nil {
+		errString = r.Error.Error()
+	}
+	return json.Marshal(struct {
+		*scanResult
+		Error string `json:",omitempty"`
+	}{
+		scanResult: (*scanResult)(&r),
+		Error:      errString,
+	})
+}
+
 // WithConfig sets the config to use for the scan
 func (s *ScanSpecs) WithFlags(flags *pflag.FlagSet) *ScanSpecs {
 	s.flags = flags
 	return s
 }
 
-// ScanLicenseText scans the specified license file to retrieve license information
+// ScanLicenseText scans the specified license file to retrieve license information.
+// The license library is loaded for each call. Use a Scanner to load it once for many scans.
 func (s *ScanSpecs) ScanLicenseText() ([]*ScanResult, error) {
 	cfg, err := configurer.InitConfig(s.flags)
 	if err != nil {
 		return nil, err
 	}
-	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	licenseScanner, err := New(Options{Config: cfg})
 	if err != nil {
 		return nil, err
 	}
+	return licenseScanner.ScanSpecs(*s), nil
+}
 
-	// initialize the license data set to compare against
-	if err := licenseLibrary.AddAll(); err != nil {
-		return nil, err
-	}
+// ScanLicenseText scans the specified license file to retrieve license information
+func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resultsCache map[normalizer.Digest]*ScanResult) *ScanResult {
+	return s.ScanLicenseTextWithCache(licenseLibrary, mapResultsCache(resultsCache))
+}
 
-	var r []*ScanResult
+// ScanLicenseTextWithCache scans the specified license text, using and updating the results cache
+func (s *ScanSpec) ScanLicenseTextWithCache(licenseLibrary *licenses.LicenseLibrary, resultsCache ResultsCache) *ScanResult {
+	return s.ScanLicenseTextWithCacheContext(context.Background(), licenseLibrary, resultsCache)
+}
 
-	// resultsCache is a local cache holding the results of scanned license text
-	// this cache is searched before every scan to get the scan results if they exist
-	// this cache is updated after every new license match found
-	resultsCache := make(map[normalizer.Digest]*ScanResult)
+// ScanLicenseTextWithCacheContext is ScanLicenseTextWithCache until the context is done.
+// If the context is done first, the context error is returned in the ScanResult.Error (and the result is not cached).
+func (s *ScanSpec) ScanLicenseTextWithCacheContext(ctx context.Context, licenseLibrary *licenses.LicenseLibrary, resultsCache ResultsCache) *ScanResult {
+	return s.scanLicenseText(ctx, identifier.Options{}, licenseLibrary, resultsCache)
+}
 
-	for _, p := range s.Specs {
-		// identify license information for the specified license text
-		scanResult := p.ScanLicenseText(licenseLibrary, resultsCache)
-		r = append(r, scanResult)
+// fileName is the location or the name of the spec, used to select the comment style of a source file
+func (s *ScanSpec) fileName() string {
+	if s.Location != "" {
+		return s.Location
 	}
-	return r, nil
+	return s.Name
 }
 
-// ScanLicenseText scans the specified license file to retrieve license information
-func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resultsCache map[normalizer.Digest]*ScanResult) *ScanResult {
+// scanLicenseText scans the license text with the identifier options (e.g. the scheduler of a Scanner)
+func (s *ScanSpec) scanLicenseText(ctx context.Context, options identifier.Options, licenseLibrary *licenses.LicenseLibrary, resultsCache ResultsCache) *ScanResult {
 	// create a scanResult with the specifications and licenseText
 	r := &ScanResult{
 		Spec:              *s,
@@ -148,13 +177,26 @@ func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resu
 		CycloneDXLicenses: Licenses{},
 	}
 
+	// a spec with only a hash is identified if it is the hash of a known license text
+	if s.LicenseText == "" && s.Hash != nil {
+		if ids, ok := licenseLibrary.KnownHashes[s.Hash.Sha256]; ok && s.Hash.Sha256 != "" {
+			r.Hash = s.Hash
+			for _, id := range ids {
+				r.CycloneDXLicenses = append(r.CycloneDXLicenses, newLicenseChoice(licenseLibrary, id))
+			}
+			return r
+		}
+	}
+
 	// instantiate normalizedData with the input license text
 	normalizedData := normalizer.NormalizationData{
 		OriginalText: s.LicenseText,
+		CommentStyle: licenseLibrary.CommentStyle(s.fileName(), s.LicenseText),
+		Markup:       normalizer.DetectMarkup(s.fileName(), s.LicenseText),
 	}
 
 	// normalize the input license text
-	if err := normalizedData.NormalizeText(); err != nil {
+	if err := options.Normalize(&normalizedData, licenseLibrary); err != nil {
 		r.Error = err
 		return r
 	}
@@ -165,13 +207,17 @@ func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resu
 
 	// check the cache in memory if we have seen the same license before
 	// return the result if it exists in the cache to avoid running identification for it
-	if cachedResult, ok := resultsCache[*r.Hash]; ok {
-		return cachedResult
+	// the cached result may be for another spec, so the copy has this spec and original text
+	if cachedResult, ok := resultsCache.Get(*r.Hash); ok {
+		cached := cachedResult.copy()
+		cached.Spec = *s
+		cached.OriginalText = s.LicenseText
+		return cached
 	}
 
 	// find the licenses in the normalized text and return a list of SPDX IDs
 	// in case of an error, return as much as we have along with an error
-	results, err := identifier.Identify(identifier.Options{}, licenseLibrary, normalizedData)
+	results, err := identifier.IdentifyContext(ctx, options, licenseLibrary, normalizedData)
 	if err != nil {
 		r.Error = err
 		return r
@@ -190,46 +236,75 @@ func (s *ScanSpec) ScanLicenseText(licenseLibrary *licenses.LicenseLibrary, resu
 		for id := range results.Matches {
 			// Add an SPDX ID from the match
 			// update the LicenseChoice to include each new match
-
-			// Add suffix of (family) to the name, if we have a family
-			family := licenseLibrary.LicenseMap[id].LicenseInfo.Family
-			name := licenseLibrary.LicenseMap[id].LicenseInfo.Name
-			if family != "" {
-				name = fmt.Sprintf("%s (%s)", name, family)
-			}
-			r.CycloneDXLicenses = append(r.CycloneDXLicenses, LicenseChoice{
-				License: &License{
-					ID:   id,
-					Name: name,
-					// TODO: verify whether this is acceptable or just expect a single license here
-					URL: strings.Join(licenseLibrary.LicenseMap[id].LicenseInfo.URLs, ","),
-					Text: &AttachedText{
-						Content:     licenseLibrary.LicenseMap[id].Text.Content,
-						ContentType: licenseLibrary.LicenseMap[id].Text.ContentType,
-						Encoding:    licenseLibrary.LicenseMap[id].Text.Encoding,
-					},
-				},
-			})
-
+			r.CycloneDXLicenses = append(r.CycloneDXLicenses, newLicenseChoice(licenseLibrary, id))
 		}
 	}
 
 	// populate the results cache to keep the match in memory for next license match
-	resultsCache[*r.Hash] = r
+	// the cache may be shared (e.g. by the servers of a Scanner), so it has a copy which the caller cannot change
+	resultsCache.Put(*r.Hash, r.copy())
 
 	return r
 }
 
-// ScanFile looks up a specific file by name to retrieve license data.
-// If the license data is not available, scan the specified file,
-// persist the scanned result into a datastore, and return the license data.
-func (s *ScanSpecs) ScanFile() []*ScanResult {
-	var r []*ScanResult
-	// identify license information for each specified package
-	for _, p := range s.Specs {
-		r = append(r, &ScanResult{
-			Spec: p,
-		})
+// copy returns a copy of the result which shares nothing that a caller may change with the result
+func (r *ScanResult) copy() *ScanResult {
+	c := *r
+	if r.Hash != nil {
+		hash := *r.Hash
+		c.Hash = &hash
 	}
-	return r
+	if r.CycloneDXLicenses != nil {
+		c.CycloneDXLicenses = make(Licenses, len(r.CycloneDXLicenses))
+		for i, choice := range r.CycloneDXLicenses {
+			if choice.License != nil {
+				license := *choice.License
+				if license.Text != nil {
+					text := *license.Text
+					license.Text = &text
+				}
+				choice.License = &license
+			}
+			c.CycloneDXLicenses[i] = choice
+		}
+	}
+	return &c
+}
+
+// newLicenseChoice returns the LicenseChoice for a license ID in the library
+func newLicenseChoice(licenseLibrary *licenses.LicenseLibrary, id string) LicenseChoice {
+	// Add suffix of (family) to the name, if we have a family
+	family := licenseLibrary.LicenseMap[id].LicenseInfo.Family
+	name := licenseLibrary.LicenseMap[id].LicenseInfo.Name
+	if family != "" {
+		name = fmt.Sprintf("%s (%s)", name, family)
+	}
+	return LicenseChoice{
+		License: &License{
+			ID:   id,
+			Name: name,
+			// TODO: verify whether this is acceptable or just expect a single license here
+			URL: strings.Join(licenseLibrary.LicenseMap[id].LicenseInfo.URLs, ","),
+			Text: &AttachedText{
+				Content:     licenseLibrary.LicenseMap[id].Text.Content,
+				ContentType: licenseLibrary.LicenseMap[id].Text.ContentType,
+				Encoding:    licenseLibrary.LicenseMap[id].Text.Encoding,
+			},
+		},
+	}
+}
+
+// ScanFile reads each spec's file from its Location (see ReadLocation) and scans it to retrieve license information.
+// If the Location is empty, the Name is used as the location. Read errors are reported in the ScanResult.Error of the spec.
+// The license library is loaded for each call. Use a Scanner to load it once for many scans.
+func (s *ScanSpecs) ScanFile() ([]*ScanResult, error) {
+	cfg, err := configurer.InitConfig(s.flags)
+	if err != nil {
+		return nil, err
+	}
+	licenseScanner, err := New(Options{Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	return licenseScanner.ScanLocations(*s), nil
 }
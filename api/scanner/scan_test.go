@@ -7,7 +7,10 @@ package scanner_test
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -202,32 +205,58 @@ func TestScanSpec_ScanLicenseText_With_CachedResults(t *testing.T) {
 }
 
 func TestScanSpecs_ScanFile(t *testing.T) {
-	async_specs := scanner.ScanSpec{
-		Name:     "async",
-		Version:  "3.2.2",
-		Location: "https://github.com/caolan/async/",
-	}
-	sps := []struct {
-		name           string
-		spec           *scanner.ScanSpecs
-		expectedResult []*scanner.ScanResult
-	}{{
-		name: "scan license file",
-		spec: &scanner.ScanSpecs{
-			PackageManager: "npm",
-			Specs:          []scanner.ScanSpec{async_specs},
+	dir := t.TempDir()
+	licensePath := filepath.Join(dir, "LICENSE")
+	if err := os.WriteFile(licensePath, []byte(mitText), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// a UTF-16LE file with a byte order mark, e.g. from Windows tooling
+	utf16Path := filepath.Join(dir, "LICENSE.utf16")
+	utf16Text := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(mitText)) {
+		utf16Text = append(utf16Text, byte(u), byte(u>>8))
+	}
+	if err := os.WriteFile(utf16Path, utf16Text, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	specs := &scanner.ScanSpecs{
+		PackageManager: "npm",
+		Specs: []scanner.ScanSpec{
+			{Name: "async", Location: licensePath},
+			{Name: "async", Location: "file://" + filepath.ToSlash(licensePath)},
+			{Name: licensePath},
+			{Name: "missing", Location: filepath.Join(dir, "missing")},
+			{Name: "async", Version: "3.2.2", Location: "https://github.com/caolan/async/"},
+			{Name: "async", Location: utf16Path},
 		},
-		expectedResult: []*scanner.ScanResult{{
-			Spec: async_specs,
-		}},
-	}}
-
-	for _, sp := range sps {
-		t.Run(sp.name, func(t *testing.T) {
-			actualResult := sp.spec.ScanFile()
-			if d := cmp.Diff(sp.expectedResult, actualResult); d != "" {
-				t.Errorf("Didn't get expected License match: %s", fmt.Sprintf("(-want, +got): %s", d))
-			}
-		})
+	}
+	results, err := specs.ScanFile()
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+
+	wantIDs := [][]string{{"MIT"}, {"MIT"}, {"MIT"}, nil, nil, {"MIT"}}
+	wantErr := []bool{false, false, false, true, true, false}
+	wantEncoding := []string{"UTF-8", "UTF-8", "UTF-8", "", "", "UTF-16LE"}
+	if len(results) != len(wantIDs) {
+		t.Fatalf("expected %v results, got %v", len(wantIDs), len(results))
+	}
+	for i, r := range results {
+		if d := cmp.Diff(specs.Specs[i].Location, r.Spec.Location); d != "" {
+			t.Errorf("result %v didn't get expected spec: (-want, +got): %v", i, d)
+		}
+		if (r.Error != nil) != wantErr[i] {
+			t.Errorf("result %v error = %v, wantErr %v", i, r.Error, wantErr[i])
+		}
+		if !wantErr[i] && r.Hash == nil {
+			t.Errorf("result %v expected a hash", i)
+		}
+		if d := cmp.Diff(wantIDs[i], licenseIDs(r)); d != "" {
+			t.Errorf("result %v didn't get expected license IDs: (-want, +got): %v", i, d)
+		}
+		if d := cmp.Diff(wantEncoding[i], r.Encoding); d != "" {
+			t.Errorf("result %v didn't get expected encoding: (-want, +got): %v", i, d)
+		}
 	}
 }
@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/identifier"
+	"github.com/IBM/license-scanner/licenses"
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// Options configure a Scanner
+type Options struct {
+	// Config selects the license library resources. If nil, the default config is used.
+	Config *viper.Viper
+	// LicenseLibrary is a license library which is already loaded. If set, Config is not used.
+	LicenseLibrary *licenses.LicenseLibrary
+	// Identifier options are used by ScanFile and ScanDirectory (the Scheduler and Pipeline are used by all the scans)
+	Identifier identifier.Options
+	// ResultsCache is shared by all the text scans. If nil, the results cache directory from the config is used
+	// (see NewDirResultsCache), or if there is none, duplicate texts are only reused within a call.
+	// It must be safe for concurrent use if the Scanner is used concurrently (e.g. NewLRUResultsCache).
+	ResultsCache ResultsCache
+	// Timeout is the deadline for each scan call, e.g. each ScanSpecs or ScanDirectory (no deadline if zero)
+	Timeout time.Duration
+	// Workers limits the files and patterns identified at once by all the scans of the Scanner.
+	// If zero, the scans share the default scheduler with GOMAXPROCS workers. Not used if Identifier.Scheduler is set.
+	Workers int
+}
+
+// Scanner identifies licenses with a license library which is loaded once.
+// A Scanner is safe for concurrent use.
+type Scanner struct {
+	licenseLibrary *licenses.LicenseLibrary
+	options        identifier.Options
+	resultsCache   ResultsCache
+	timeout        time.Duration
+}
+
+// New loads the license library (unless one is provided in the options) and returns a Scanner
+func New(options Options) (*Scanner, error) {
+	cfg := options.Config
+	licenseLibrary := options.LicenseLibrary
+	if licenseLibrary == nil {
+		var err error
+		if cfg == nil {
+			if cfg, err = configurer.InitConfig(nil); err != nil {
+				return nil, err
+			}
+		}
+		if licenseLibrary, err = licenses.NewLicenseLibrary(cfg); err != nil {
+			return nil, err
+		}
+		if err := licenseLibrary.AddAll(); err != nil {
+			return nil, err
+		}
+	} else if cfg == nil {
+		cfg = licenseLibrary.Config
+	}
+
+	resultsCache := options.ResultsCache
+	if resultsCache == nil && cfg != nil && cfg.GetString(configurer.ResultsCacheFlag) != "" {
+		fingerprint, err := licenseLibrary.Fingerprint()
+		if err != nil {
+			return nil, err
+		}
+		if resultsCache, err = NewDirResultsCache(cfg.GetString(configurer.ResultsCacheFlag), fingerprint); err != nil {
+			return nil, err
+		}
+	}
+
+	identifierOptions := options.Identifier
+	if identifierOptions.Scheduler == nil && options.Workers > 0 {
+		identifierOptions.Scheduler = identifier.NewScheduler(options.Workers)
+	}
+
+	return &Scanner{
+		licenseLibrary: licenseLibrary,
+		options:        identifierOptions,
+		resultsCache:   resultsCache,
+		timeout:        options.Timeout,
+	}, nil
+}
+
+// NewWithLibrary returns a Scanner with default options using a license library which is already loaded
+func NewWithLibrary(licenseLibrary *licenses.LicenseLibrary) *Scanner {
+	return &Scanner{licenseLibrary: licenseLibrary}
+}
+
+// NewFromConfig returns a Scanner using the config for the license library and for the identifier options
+// (the same options as the license-scanner command)
+func NewFromConfig(cfg *viper.Viper) (*Scanner, error) {
+	return New(Options{
+		Config:     cfg,
+		Identifier: IdentifierOptions(cfg),
+		Timeout:    cfg.GetDuration(configurer.TimeoutFlag),
+		Workers:    cfg.GetInt(configurer.WorkersFlag),
+	})
+}
+
+// IdentifierOptions returns the identifier options selected by the config flags
+func IdentifierOptions(cfg *viper.Viper) identifier.Options {
+	return identifier.Options{
+		ForceResult:  true,
+		FileTimeout:  cfg.GetDuration(configurer.FileTimeoutFlag),
+		MaxFileSize:  cfg.GetInt64(configurer.MaxFileSizeFlag),
+		LargeFiles:   cfg.GetString(configurer.LargeFilesFlag),
+		ChunkOverlap: cfg.GetInt64(configurer.ChunkOverlapFlag),
+		Enhancements: identifier.Enhancements{
+			AddNotes:       "",
+			AddTextBlocks:  true,
+			FlagAcceptable: cfg.GetBool(configurer.AcceptableFlag),
+			FlagCopyrights: cfg.GetBool(configurer.CopyrightsFlag),
+			FlagKeywords:   cfg.GetBool(configurer.KeywordsFlag),
+		},
+	}
+}
+
+// LicenseLibrary returns the loaded license library. It must not be modified.
+func (s *Scanner) LicenseLibrary() *licenses.LicenseLibrary {
+	return s.licenseLibrary
+}
+
+// WithTimeout returns a context which is done after the scanner timeout (if any).
+// The scan methods apply the timeout, so it is only needed to scan with a ScanSpec directly.
+func (s *Scanner) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Workers returns the number of workers identifying files and matching patterns for the scans of the Scanner
+func (s *Scanner) Workers() int {
+	if s.options.Scheduler == nil {
+		return runtime.GOMAXPROCS(0) // the default scheduler
+	}
+	return s.options.Scheduler.Workers()
+}
+
+// ScanWithCacheContext identifies the licenses in the license text of a spec using the results cache, e.g. a cache
+// for a batch of specs. The scanner timeout is not applied (see WithTimeout).
+func (s *Scanner) ScanWithCacheContext(ctx context.Context, spec ScanSpec, resultsCache ResultsCache) *ScanResult {
+	return spec.scanLicenseText(ctx, s.scanOptions(), s.licenseLibrary, resultsCache)
+}
+
+// scanOptions are the identifier options for text scans, which only use the scheduler and pipeline of the scanner
+func (s *Scanner) scanOptions() identifier.Options {
+	return identifier.Options{Scheduler: s.options.Scheduler, Pipeline: s.options.Pipeline}
+}
+
+// ScanText identifies the licenses in a license text
+func (s *Scanner) ScanText(name string, licenseText string) *ScanResult {
+	return s.Scan(ScanSpec{Name: name, LicenseText: licenseText})
+}
+
+// Scan identifies the licenses in the license text of a spec
+func (s *Scanner) Scan(spec ScanSpec) *ScanResult {
+	return s.ScanContext(context.Background(), spec)
+}
+
+// ScanContext identifies the licenses in the license text of a spec until the context is done
+func (s *Scanner) ScanContext(ctx context.Context, spec ScanSpec) *ScanResult {
+	return s.ScanSpecsContext(ctx, ScanSpecs{Specs: []ScanSpec{spec}})[0]
+}
+
+// ScanSpecs identifies the licenses in the license text of each spec.
+// The results are in the same order as the specs.
+func (s *Scanner) ScanSpecs(specs ScanSpecs) []*ScanResult {
+	return s.ScanSpecsContext(context.Background(), specs)
+}
+
+// ScanSpecsContext identifies the licenses in the license text of each spec until the context is done.
+// The specs which were not completed have the context error in their ScanResult.Error.
+func (s *Scanner) ScanSpecsContext(ctx context.Context, specs ScanSpecs) []*ScanResult {
+	ctx, cancel := s.WithTimeout(ctx)
+	defer cancel()
+	resultsCache := s.results()
+	results := make([]*ScanResult, 0, len(specs.Specs))
+	for i := range specs.Specs {
+		results = append(results, specs.Specs[i].scanLicenseText(ctx, s.scanOptions(), s.licenseLibrary, resultsCache))
+	}
+	return results
+}
+
+// ScanLocation reads the file at the Location of the spec (or the Name if there is no Location) and identifies its licenses.
+// See ReadLocation for the supported locations. A read error is returned in the ScanResult.Error.
+func (s *Scanner) ScanLocation(spec ScanSpec) *ScanResult {
+	return s.ScanLocations(ScanSpecs{Specs: []ScanSpec{spec}})[0]
+}
+
+// ScanLocations reads the file at the Location of each spec and identifies its licenses.
+// The results are in the same order as the specs.
+func (s *Scanner) ScanLocations(specs ScanSpecs) []*ScanResult {
+	return s.ScanLocationsContext(context.Background(), specs)
+}
+
+// ScanLocationsContext reads the file at the Location of each spec and identifies its licenses until the context is done.
+// The specs which were not completed have the context error in their ScanResult.Error.
+func (s *Scanner) ScanLocationsContext(ctx context.Context, specs ScanSpecs) []*ScanResult {
+	ctx, cancel := s.WithTimeout(ctx)
+	defer cancel()
+	resultsCache := s.results()
+	results := make([]*ScanResult, 0, len(specs.Specs))
+	for _, spec := range specs.Specs {
+		if err := ctx.Err(); err != nil {
+			results = append(results, &ScanResult{Spec: spec, Error: err, CycloneDXLicenses: Licenses{}})
+			continue
+		}
+		location := spec.Location
+		if location == "" {
+			location = spec.Name
+		}
+		b, err := ReadLocation(location)
+		if err != nil {
+			results = append(results, &ScanResult{
+				Spec:              spec,
+				Error:             fmt.Errorf("read location error: %w", err),
+				CycloneDXLicenses: Licenses{},
+			})
+			continue
+		}
+		decoded := normalizer.DecodeText(b)
+		spec.LicenseText = decoded.Text
+		// a copy, because the result may be in the results cache
+		result := *spec.scanLicenseText(ctx, s.scanOptions(), s.licenseLibrary, resultsCache)
+		result.Encoding = decoded.Encoding
+		results = append(results, &result)
+	}
+	return results
+}
+
+// results returns the shared results cache, or a cache for one call
+func (s *Scanner) results() ResultsCache {
+	if s.resultsCache == nil {
+		return mapResultsCache{}
+	}
+	return s.resultsCache
+}
+
+// ScanFile identifies the licenses in a file
+func (s *Scanner) ScanFile(filePath string) (identifier.IdentifierResults, error) {
+	return s.ScanFileContext(context.Background(), filePath)
+}
+
+// ScanFileContext identifies the licenses in a file until the context is done
+func (s *Scanner) ScanFileContext(ctx context.Context, filePath string) (identifier.IdentifierResults, error) {
+	ctx, cancel := s.WithTimeout(ctx)
+	defer cancel()
+	return identifier.IdentifyLicensesInFileContext(ctx, filePath, s.options, s.licenseLibrary)
+}
+
+// ScanDirectory identifies the licenses in each file in a directory tree
+func (s *Scanner) ScanDirectory(dirPath string) ([]identifier.IdentifierResults, error) {
+	return s.ScanDirectoryContext(context.Background(), dirPath)
+}
+
+// ScanDirectoryContext identifies the licenses in each file in a directory tree until the context is done.
+// The files which were not completed are reported in an *identifier.IncompleteFilesError with the completed results.
+func (s *Scanner) ScanDirectoryContext(ctx context.Context, dirPath string) ([]identifier.IdentifierResults, error) {
+	ctx, cancel := s.WithTimeout(ctx)
+	defer cancel()
+	return identifier.IdentifyLicensesInDirectoryContext(ctx, dirPath, s.options, s.licenseLibrary)
+}
+
+// ScanDirectoryFunc identifies the licenses in each file in a directory tree and calls fn with each result as soon as
+// it is ready, without keeping the results in memory. See identifier.IdentifyLicensesInDirectoryFunc.
+func (s *Scanner) ScanDirectoryFunc(ctx context.Context, dirPath string, fn identifier.ResultFunc) error {
+	ctx, cancel := s.WithTimeout(ctx)
+	defer cancel()
+	return identifier.IdentifyLicensesInDirectoryFunc(ctx, dirPath, s.options, s.licenseLibrary, fn)
+}
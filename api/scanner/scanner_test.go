@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package scanner_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/identifier"
+)
+
+const mitText = `Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.`
+
+func licenseIDs(r *scanner.ScanResult) []string {
+	var ids []string
+	for _, lc := range r.CycloneDXLicenses {
+		if lc.License != nil {
+			ids = append(ids, lc.License.ID)
+		}
+	}
+	return ids
+}
+
+func TestScanner(t *testing.T) {
+	licenseScanner, err := scanner.New(scanner.Options{ResultsCache: scanner.NewSyncResultsCache()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Run("concurrent text scans", func(t *testing.T) {
+		const n = 8
+		results := make([]*scanner.ScanResult, n)
+		wg := sync.WaitGroup{}
+		for i := 0; i < n; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = licenseScanner.ScanText("LICENSE", mitText)
+			}()
+		}
+		wg.Wait()
+		for i, r := range results {
+			if r.Error != nil {
+				t.Fatalf("scan %v error = %v", i, r.Error)
+			}
+			if d := cmp.Diff([]string{"MIT"}, licenseIDs(r)); d != "" {
+				t.Errorf("scan %v didn't get expected license IDs: (-want, +got): %v", i, d)
+			}
+		}
+	})
+
+	t.Run("changed results do not change the cached results", func(t *testing.T) {
+		for i := 0; i < 2; i++ { // a miss (or a hit from the concurrent scans) and a hit
+			r := licenseScanner.ScanText("LICENSE", mitText)
+			r.CycloneDXLicenses[0].License.ID = "changed"
+			r.CycloneDXLicenses = append(r.CycloneDXLicenses, scanner.LicenseChoice{Expression: "changed"})
+			r.Hash.Sha256 = "changed"
+		}
+		if d := cmp.Diff([]string{"MIT"}, licenseIDs(licenseScanner.ScanText("LICENSE", mitText))); d != "" {
+			t.Errorf("Didn't get expected license IDs: (-want, +got): %v", d)
+		}
+	})
+
+	t.Run("specs", func(t *testing.T) {
+		results := licenseScanner.ScanSpecs(scanner.ScanSpecs{Specs: []scanner.ScanSpec{
+			{Name: "empty"},
+			{Name: "mit", LicenseText: mitText},
+		}})
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %v", len(results))
+		}
+		if results[0].Error == nil {
+			t.Error("expected an error for empty text")
+		}
+		if d := cmp.Diff([]string{"MIT"}, licenseIDs(results[1])); d != "" {
+			t.Errorf("Didn't get expected license IDs: (-want, +got): %v", d)
+		}
+	})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "LICENSE"), []byte(mitText), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("no license here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("file", func(t *testing.T) {
+		result, err := licenseScanner.ScanFile(filepath.Join(dir, "LICENSE"))
+		if err != nil {
+			t.Fatalf("ScanFile() error = %v", err)
+		}
+		if _, ok := result.Matches["MIT"]; !ok {
+			t.Errorf("expected MIT, got %v", result.Matches)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		results, err := licenseScanner.ScanDirectory(dir)
+		if err != nil {
+			t.Fatalf("ScanDirectory() error = %v", err)
+		}
+		got := make(map[string]int)
+		for _, r := range results {
+			got[filepath.Base(r.File)] = len(r.Matches)
+		}
+		if d := cmp.Diff(map[string]int{"LICENSE": 1, "README": 0}, got); d != "" {
+			t.Errorf("Didn't get expected matches per file: (-want, +got): %v", d)
+		}
+	})
+}
+
+func TestScanner_Context(t *testing.T) {
+	licenseScanner, err := scanner.New(scanner.Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Run("cancelled specs", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := licenseScanner.ScanSpecsContext(ctx, scanner.ScanSpecs{Specs: []scanner.ScanSpec{
+			{Name: "mit", LicenseText: mitText},
+		}})
+		if !errors.Is(results[0].Error, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, results[0].Error)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		timeoutScanner, err := scanner.New(scanner.Options{
+			LicenseLibrary: licenseScanner.LicenseLibrary(),
+			Timeout:        time.Nanosecond,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "LICENSE"), []byte(mitText+"\n\nand more"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err = timeoutScanner.ScanDirectory(dir)
+		var incomplete *identifier.IncompleteFilesError
+		if !errors.As(err, &incomplete) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected an IncompleteFilesError for the deadline, got %v", err)
+		}
+	})
+}
+
+func TestScanner_Workers(t *testing.T) {
+	licenseScanner, err := scanner.New(scanner.Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := licenseScanner.Workers(); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("expected GOMAXPROCS workers by default, got %v", got)
+	}
+
+	twoWorkers, err := scanner.New(scanner.Options{LicenseLibrary: licenseScanner.LicenseLibrary(), Workers: 2})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := twoWorkers.Workers(); got != 2 {
+		t.Errorf("expected 2 workers, got %v", got)
+	}
+	if d := cmp.Diff([]string{"MIT"}, licenseIDs(twoWorkers.ScanText("LICENSE", mitText+"\n\nfor the project"))); d != "" {
+		t.Errorf("Didn't get expected license IDs: (-want, +got): %v", d)
+	}
+}
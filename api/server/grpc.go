@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative scannerpb/scanner.proto
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/api/server/scannerpb"
+	"github.com/IBM/license-scanner/licenses"
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// GRPCServer implements the scannerpb.LicenseScannerServer gRPC service
+type GRPCServer struct {
+	scannerpb.UnimplementedLicenseScannerServer
+	scanner *scanner.Scanner
+	// workers limits the concurrent scans per stream
+	workers int
+}
+
+// NewGRPCServer returns a gRPC service using a license library which is already loaded.
+// Register it with scannerpb.RegisterLicenseScannerServer.
+func NewGRPCServer(licenseLibrary *licenses.LicenseLibrary) *GRPCServer {
+	return NewGRPCServerWithScanner(scanner.NewWithLibrary(licenseLibrary))
+}
+
+// NewGRPCServerWithScanner returns a gRPC service using a Scanner, which can be shared with other servers
+func NewGRPCServerWithScanner(licenseScanner *scanner.Scanner) *GRPCServer {
+	return &GRPCServer{
+		scanner: licenseScanner,
+		workers: licenseScanner.Workers(),
+	}
+}
+
+// Scan identifies the licenses in one license text
+func (s *GRPCServer) Scan(ctx context.Context, req *scannerpb.ScanRequest) (*scannerpb.ScanResponse, error) {
+	ctx, cancel := s.scanner.WithTimeout(ctx)
+	defer cancel()
+	return s.scan(ctx, req, scanner.NewSyncResultsCache()), nil
+}
+
+// ScanStream identifies the licenses in a stream of license texts and streams back the results as they complete.
+// At most workers requests are scanned at once, so a client that sends faster than the scans complete
+// (or does not read the responses) is held back by flow control.
+func (s *GRPCServer) ScanStream(stream scannerpb.LicenseScanner_ScanStreamServer) error {
+	// The results cache is shared by the stream, so identical texts are only identified once
+	resultsCache := scanner.NewSyncResultsCache()
+	// The scanner timeout is the deadline for the whole stream. Requests which are not completed get the context error.
+	ctx, cancel := s.scanner.WithTimeout(stream.Context())
+	defer cancel()
+
+	responses := make(chan *scannerpb.ScanResponse, s.workers)
+	sendErr := make(chan error, 1)
+	go func() {
+		var err error
+		for response := range responses {
+			if err == nil {
+				err = stream.Send(response)
+			}
+			// After a send error, keep draining so the workers finish
+		}
+		sendErr <- err
+	}()
+
+	workers := errgroup.Group{}
+	workers.SetLimit(s.workers)
+	var recvErr error
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			recvErr = err
+			break
+		}
+		// Go blocks while all the workers are busy, which stops receiving
+		workers.Go(func() error {
+			responses <- s.scan(ctx, req, resultsCache)
+			return nil
+		})
+	}
+	_ = workers.Wait()
+	close(responses)
+
+	if err := <-sendErr; err != nil {
+		return err
+	}
+	return recvErr
+}
+
+func (s *GRPCServer) scan(ctx context.Context, req *scannerpb.ScanRequest, resultsCache scanner.ResultsCache) *scannerpb.ScanResponse {
+	spec := scanner.ScanSpec{
+		Name:        req.GetName(),
+		Version:     req.GetVersion(),
+		Location:    req.GetLocation(),
+		PURL:        req.GetPurl(),
+		Hash:        fromDigestPB(req.GetHash()),
+		LicenseText: req.GetLicenseText(),
+	}
+	result := s.scanner.ScanWithCacheContext(ctx, spec, resultsCache)
+
+	response := &scannerpb.ScanResponse{
+		Id: req.GetId(),
+		// The result may be a cached result for another request, so the spec is from this request
+		Spec: &scannerpb.ScanRequest{
+			Id:       req.GetId(),
+			Name:     req.GetName(),
+			Version:  req.GetVersion(),
+			Location: req.GetLocation(),
+			Purl:     req.GetPurl(),
+			Hash:     req.GetHash(),
+		},
+		NormalizedText: result.NormalizedText,
+		Hash:           toDigestPB(result.Hash),
+	}
+	if result.Error != nil {
+		response.Error = result.Error.Error()
+	}
+	for _, lc := range result.CycloneDXLicenses {
+		choice := &scannerpb.LicenseChoice{Expression: lc.Expression}
+		if lc.License != nil {
+			choice.License = &scannerpb.License{
+				Id:   lc.License.ID,
+				Name: lc.License.Name,
+				Url:  lc.License.URL,
+			}
+			if lc.License.Text != nil {
+				choice.License.Text = &scannerpb.AttachedText{
+					Content:     lc.License.Text.Content,
+					ContentType: lc.License.Text.ContentType,
+					Encoding:    lc.License.Text.Encoding,
+				}
+			}
+		}
+		response.Licenses = append(response.Licenses, choice)
+	}
+	return response
+}
+
+func fromDigestPB(d *scannerpb.Digest) *normalizer.Digest {
+	if d == nil {
+		return nil
+	}
+	return &normalizer.Digest{Md5: d.GetMd5(), Sha256: d.GetSha256(), Sha512: d.GetSha512()}
+}
+
+func toDigestPB(d *normalizer.Digest) *scannerpb.Digest {
+	if d == nil {
+		return nil
+	}
+	return &scannerpb.Digest{Md5: d.Md5, Sha256: d.Sha256, Sha512: d.Sha512}
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/IBM/license-scanner/api/server"
+	"github.com/IBM/license-scanner/api/server/scannerpb"
+	"github.com/IBM/license-scanner/licenses"
+)
+
+func TestGRPCServer(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	scannerpb.RegisterLicenseScannerServer(grpcServer, server.NewGRPCServer(licenseLibrary))
+	go func() { _ = grpcServer.Serve(lis) }()
+	defer grpcServer.Stop()
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := scannerpb.NewLicenseScannerClient(conn)
+
+	t.Run("Scan", func(t *testing.T) {
+		got, err := client.Scan(ctx, &scannerpb.ScanRequest{Id: "1", Name: "LICENSE", LicenseText: mitText})
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if got.GetId() != "1" || got.GetSpec().GetName() != "LICENSE" || got.GetHash().GetSha256() == "" {
+			t.Errorf("unexpected response: %v", got)
+		}
+		if len(got.GetLicenses()) != 1 || got.GetLicenses()[0].GetLicense().GetId() != "MIT" {
+			t.Errorf("expected MIT, got %v", got.GetLicenses())
+		}
+	})
+
+	t.Run("ScanStream", func(t *testing.T) {
+		stream, err := client.ScanStream(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests := []*scannerpb.ScanRequest{
+			{Id: "a", Name: "a/LICENSE", LicenseText: mitText},
+			{Id: "b", Name: "b/LICENSE", LicenseText: mitText},
+			{Id: "c", Name: "c/README", LicenseText: "this is not a license"},
+			{Id: "d", Name: "d/empty"},
+		}
+		for _, req := range requests {
+			if err := stream.Send(req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := stream.CloseSend(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := make(map[string]string)
+		names := make(map[string]string)
+		for {
+			response, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, lc := range response.GetLicenses() {
+				ids = append(ids, lc.GetLicense().GetId()+lc.GetLicense().GetName())
+			}
+			sort.Strings(ids)
+			if response.GetError() != "" {
+				ids = append(ids, "error")
+			}
+			got[response.GetId()] = fmt.Sprint(ids)
+			names[response.GetId()] = response.GetSpec().GetName()
+		}
+
+		want := map[string]string{
+			"a": "[MITMIT License (MIT)]",
+			"b": "[MITMIT License (MIT)]",
+			"c": "[NOASSERTION]",
+			"d": "[error]",
+		}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Errorf("Didn't get expected responses: (-want, +got): %v", d)
+		}
+		// b is a cached result for a, but has its own spec
+		if names["b"] != "b/LICENSE" {
+			t.Errorf("expected the spec from the request, got %v", names["b"])
+		}
+	})
+}
@@ -0,0 +1,174 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: scannerpb/scanner.proto
+
+package scannerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LicenseScannerClient is the client API for LicenseScanner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LicenseScannerClient interface {
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanResponse, error)
+	ScanStream(ctx context.Context, opts ...grpc.CallOption) (LicenseScanner_ScanStreamClient, error)
+}
+
+type licenseScannerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLicenseScannerClient(cc grpc.ClientConnInterface) LicenseScannerClient {
+	return &licenseScannerClient{cc}
+}
+
+func (c *licenseScannerClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanResponse, error) {
+	out := new(ScanResponse)
+	err := c.cc.Invoke(ctx, "/licensescanner.v1.LicenseScanner/Scan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *licenseScannerClient) ScanStream(ctx context.Context, opts ...grpc.CallOption) (LicenseScanner_ScanStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LicenseScanner_ServiceDesc.Streams[0], "/licensescanner.v1.LicenseScanner/ScanStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &licenseScannerScanStreamClient{stream}
+	return x, nil
+}
+
+type LicenseScanner_ScanStreamClient interface {
+	Send(*ScanRequest) error
+	Recv() (*ScanResponse, error)
+	grpc.ClientStream
+}
+
+type licenseScannerScanStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *licenseScannerScanStreamClient) Send(m *ScanRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *licenseScannerScanStreamClient) Recv() (*ScanResponse, error) {
+	m := new(ScanResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LicenseScannerServer is the server API for LicenseScanner service.
+// All implementations must embed UnimplementedLicenseScannerServer
+// for forward compatibility
+type LicenseScannerServer interface {
+	Scan(context.Context, *ScanRequest) (*ScanResponse, error)
+	ScanStream(LicenseScanner_ScanStreamServer) error
+	mustEmbedUnimplementedLicenseScannerServer()
+}
+
+// UnimplementedLicenseScannerServer must be embedded to have forward compatible implementations.
+type UnimplementedLicenseScannerServer struct {
+}
+
+func (UnimplementedLicenseScannerServer) Scan(context.Context, *ScanRequest) (*ScanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedLicenseScannerServer) ScanStream(LicenseScanner_ScanStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ScanStream not implemented")
+}
+func (UnimplementedLicenseScannerServer) mustEmbedUnimplementedLicenseScannerServer() {}
+
+// UnsafeLicenseScannerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LicenseScannerServer will
+// result in compilation errors.
+type UnsafeLicenseScannerServer interface {
+	mustEmbedUnimplementedLicenseScannerServer()
+}
+
+func RegisterLicenseScannerServer(s grpc.ServiceRegistrar, srv LicenseScannerServer) {
+	s.RegisterService(&LicenseScanner_ServiceDesc, srv)
+}
+
+func _LicenseScanner_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LicenseScannerServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/licensescanner.v1.LicenseScanner/Scan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LicenseScannerServer).Scan(ctx, req.(*ScanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LicenseScanner_ScanStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LicenseScannerServer).ScanStream(&licenseScannerScanStreamServer{stream})
+}
+
+type LicenseScanner_ScanStreamServer interface {
+	Send(*ScanResponse) error
+	Recv() (*ScanRequest, error)
+	grpc.ServerStream
+}
+
+type licenseScannerScanStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *licenseScannerScanStreamServer) Send(m *ScanResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *licenseScannerScanStreamServer) Recv() (*ScanRequest, error) {
+	m := new(ScanRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LicenseScanner_ServiceDesc is the grpc.ServiceDesc for LicenseScanner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LicenseScanner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "licensescanner.v1.LicenseScanner",
+	HandlerType: (*LicenseScannerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Scan",
+			Handler:    _LicenseScanner_Scan_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ScanStream",
+			Handler:       _LicenseScanner_ScanStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "scannerpb/scanner.proto",
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package server serves license scanning over HTTP with a license library which is loaded once.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mrutkows/sbom-utility/log"
+	"github.com/spf13/viper"
+
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/licenses"
+)
+
+const (
+	// MaxRequestBytes limits the size of a request body (text, files or archives)
+	MaxRequestBytes = 64 << 20
+	// FileField is the multipart form field for uploaded files and archives
+	FileField = "file"
+
+	licensesPath = "/v1/licenses"
+)
+
+var Logger = log.NewLogger(log.INFO)
+
+// Server handles the license scanning endpoints:
+//
+//	POST /v1/scan          ScanSpecs JSON -> []ScanResult
+//	POST /v1/scan/text     license text -> ScanResult
+//	POST /v1/scan/file     multipart files and archives -> []ScanResult
+//	GET  /v1/licenses      the licenses and exceptions in the library
+//	GET  /v1/licenses/{id} a license with the text of its templates
+type Server struct {
+	scanner *scanner.Scanner
+	mux     *http.ServeMux
+}
+
+// LicenseList is the JSON response for the licenses in the library
+type LicenseList struct {
+	SPDXVersion          string
+	Licenses             []licenses.Detail
+	Exceptions           []licenses.Exception
+	DeprecatedLicenses   []licenses.Detail
+	DeprecatedExceptions []licenses.Exception
+}
+
+// LicenseText is the JSON response for a license and its template texts
+type LicenseText struct {
+	ID          string
+	LicenseInfo licenses.LicenseInfo
+	Templates   []licenses.PrimaryPatternsSources
+}
+
+// NewServer loads the license library using the config (or the default config if nil) and returns the server
+func NewServer(config *viper.Viper) (*Server, error) {
+	licenseScanner, err := scanner.New(scanner.Options{Config: config})
+	if err != nil {
+		return nil, err
+	}
+	return NewServerWithScanner(licenseScanner), nil
+}
+
+// NewServerWithLibrary returns a server using a license library which is already loaded
+func NewServerWithLibrary(licenseLibrary *licenses.LicenseLibrary) *Server {
+	return NewServerWithScanner(scanner.NewWithLibrary(licenseLibrary))
+}
+
+// NewServerWithScanner returns a server using a Scanner, which can be shared with other servers
+func NewServerWithScanner(licenseScanner *scanner.Scanner) *Server {
+	s := &Server{
+		scanner: licenseScanner,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/v1/scan", s.handleScanSpecs)
+	s.mux.HandleFunc("/v1/scan/text", s.handleScanText)
+	s.mux.HandleFunc("/v1/scan/file", s.handleScanFile)
+	s.mux.HandleFunc(licensesPath, s.handleLicenses)
+	s.mux.HandleFunc(licensesPath+"/", s.handleLicense)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleScanSpecs(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var specs scanner.ScanSpecs
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&specs); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid ScanSpecs JSON: %w", err))
+		return
+	}
+	writeJSON(w, s.scanner.ScanSpecsContext(r.Context(), specs))
+}
+
+func (s *Server) handleScanText(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, s.scanner.ScanContext(r.Context(), scanner.ScanSpec{Name: r.URL.Query().Get("name"), LicenseText: string(b)}))
+}
+
+func (s *Server) handleScanFile(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBytes)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var specs []scanner.ScanSpec
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if part.FormName() != FileField {
+			continue
+		}
+		b, err := io.ReadAll(part)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		name := part.FileName()
+		if scanner.IsArchive(name) {
+			archiveSpecs, err := scanner.ArchiveSpecs(name, b)
+			if errors.Is(err, scanner.ErrArchiveTooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			specs = append(specs, archiveSpecs...)
+		} else {
+			specs = append(specs, scanner.ScanSpec{Name: name, LicenseText: string(b)})
+		}
+	}
+	if len(specs) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("no files in form field %q", FileField))
+		return
+	}
+	writeJSON(w, s.scanner.ScanSpecsContext(r.Context(), scanner.ScanSpecs{Specs: specs}))
+}
+
+func (s *Server) handleLicenses(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	lics, deprecatedLics, exceptions, deprecatedExceptions := s.scanner.LicenseLibrary().List()
+	writeJSON(w, LicenseList{
+		SPDXVersion:          s.scanner.LicenseLibrary().SPDXVersion,
+		Licenses:             lics,
+		Exceptions:           exceptions,
+		DeprecatedLicenses:   deprecatedLics,
+		DeprecatedExceptions: deprecatedExceptions,
+	})
+}
+
+func (s *Server) handleLicense(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, licensesPath+"/")
+	l, ok := s.scanner.LicenseLibrary().LicenseMap[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("license %v not found", id))
+		return
+	}
+	writeJSON(w, LicenseText{
+		ID:          id,
+		LicenseInfo: l.LicenseInfo,
+		Templates:   l.PrimaryPatternsSources,
+	})
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		_ = Logger.Errorf("write response error: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct{ Error string }{Error: err.Error()})
+}
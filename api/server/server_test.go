@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package server_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/api/server"
+)
+
+const mitText = `Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.`
+
+// result is the subset of the ScanResult JSON checked by the tests
+type result struct {
+	Spec struct {
+		Name string
+	}
+	Error             string
+	CycloneDXLicenses []struct {
+		License struct {
+			ID   string
+			Name string
+		}
+	}
+}
+
+func ids(r result) []string {
+	var ret []string
+	for _, l := range r.CycloneDXLicenses {
+		ret = append(ret, l.License.ID)
+	}
+	return ret
+}
+
+func TestServer(t *testing.T) {
+	srv, err := server.NewServer(nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	t.Run("scan text", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/v1/scan/text?name=LICENSE", "text/plain", strings.NewReader(mitText))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %v", resp.StatusCode)
+		}
+		var got result
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Spec.Name != "LICENSE" {
+			t.Errorf("Spec.Name = %v", got.Spec.Name)
+		}
+		if d := cmp.Diff([]string{"MIT"}, ids(got)); d != "" {
+			t.Errorf("Didn't get expected license IDs: (-want, +got): %v", d)
+		}
+	})
+
+	t.Run("scan specs with error as string", func(t *testing.T) {
+		body := `{"Specs": [{"Name": "empty", "LicenseText": ""}, {"Name": "mit", "LicenseText": ` + strings.TrimSpace(mustJSON(t, mitText)) + `}]}`
+		resp, err := http.Post(ts.URL+"/v1/scan", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got []result
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 results, got %v", len(got))
+		}
+		if got[0].Error == "" {
+			t.Error("expected an error for empty text")
+		}
+		if d := cmp.Diff([]string{"MIT"}, ids(got[1])); d != "" {
+			t.Errorf("Didn't get expected license IDs: (-want, +got): %v", d)
+		}
+	})
+
+	t.Run("scan file and archive", func(t *testing.T) {
+		var zipBytes bytes.Buffer
+		zw := zip.NewWriter(&zipBytes)
+		w, err := zw.Create("pkg/LICENSE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(mitText))
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for name, b := range map[string][]byte{"LICENSE.txt": []byte(mitText), "dist.zip": zipBytes.Bytes()} {
+			fw, err := mw.CreateFormFile(server.FileField, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = fw.Write(b)
+		}
+		if err := mw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(ts.URL+"/v1/scan/file", mw.FormDataContentType(), &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got []result
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 results, got %v", len(got))
+		}
+		for _, r := range got {
+			if d := cmp.Diff([]string{"MIT"}, ids(r)); d != "" {
+				t.Errorf("Didn't get expected license IDs for %v: (-want, +got): %v", r.Spec.Name, d)
+			}
+		}
+	})
+
+	t.Run("zip bomb", func(t *testing.T) {
+		var zipBytes bytes.Buffer
+		zw := zip.NewWriter(&zipBytes)
+		w, err := zw.Create("LICENSE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		zeros := make([]byte, 1<<20)
+		for i := 0; i < scanner.MaxArchiveFileBytes>>20; i++ {
+			_, _ = w.Write(zeros)
+		}
+		_, _ = w.Write([]byte{0})
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile(server.FileField, "bomb.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write(zipBytes.Bytes())
+		if err := mw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(ts.URL+"/v1/scan/file", mw.FormDataContentType(), &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if d := cmp.Diff(http.StatusRequestEntityTooLarge, resp.StatusCode); d != "" {
+			t.Errorf("Didn't get expected status: (-want, +got): %v", d)
+		}
+	})
+
+	t.Run("licenses", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/v1/licenses")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got server.LicenseList
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.SPDXVersion == "" || len(got.Licenses) == 0 || len(got.Exceptions) == 0 {
+			t.Errorf("expected SPDX version, licenses and exceptions, got %v, %v, %v", got.SPDXVersion, len(got.Licenses), len(got.Exceptions))
+		}
+	})
+
+	t.Run("license text", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/v1/licenses/MIT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got server.LicenseText
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "MIT" || len(got.Templates) == 0 || !strings.Contains(got.Templates[0].SourceText, "Permission is hereby granted") {
+			t.Errorf("unexpected license text response: %+v", got)
+		}
+	})
+
+	errorTests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "unknown license", method: http.MethodGet, path: "/v1/licenses/NOPE", want: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodGet, path: "/v1/scan/text", want: http.StatusMethodNotAllowed},
+		{name: "invalid specs", method: http.MethodPost, path: "/v1/scan", want: http.StatusBadRequest},
+	}
+	for _, tt := range errorTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader("not json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %v, want %v", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
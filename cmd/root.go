@@ -3,10 +3,13 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/mrutkows/sbom-utility/log"
@@ -14,11 +17,14 @@ import (
 	"github.com/spf13/cobra/doc"
 	"github.com/spf13/viper"
 
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/compatibility"
 	"github.com/IBM/license-scanner/configurer"
 	"github.com/IBM/license-scanner/debugger"
 	"github.com/IBM/license-scanner/identifier"
 	"github.com/IBM/license-scanner/importer"
 	"github.com/IBM/license-scanner/licenses"
+	"github.com/IBM/license-scanner/normalizer"
 )
 
 const (
@@ -85,8 +91,12 @@ Please give us feedback at: https://github.com/IBM/license-scanner/issues
 				return findLicensesInDirectory(cfg)
 			} else if cfg.GetBool(configurer.ListFlag) {
 				return listLicenses(cfg)
+			} else if ids := cfg.GetString(configurer.CheckIDsFlag); ids != "" {
+				return checkCompatibility(cfg, strings.Split(ids, ","))
 			} else if cfg.GetString(configurer.AddAllFlag) != "" {
 				return importer.AddAllSPDXTemplates(cfg)
+			} else if cfg.GetBool(configurer.KnownHashesFlag) {
+				return importer.WriteKnownHashes(cfg)
 			} else if cfg.GetString(configurer.AddPatternFlag) != "" {
 				// Otherwise, if addPattern was requested, attempt to add that pattern.
 				return errors.New("add_pattern_from_spdx() is NOT-IMPLEMENTED")
@@ -97,6 +107,7 @@ Please give us feedback at: https://github.com/IBM/license-scanner/issues
 		},
 	}
 	notGlobalInit(cmd)
+	cmd.AddCommand(NewServeCmd())
 	return cmd
 }
 
@@ -148,74 +159,119 @@ func listLicenses(cfg *viper.Viper) error {
 		licenseListVersion = fmt.Sprintf("  (SPDX license list %v)", spdxVersion)
 	}
 	fmt.Println("## Runtime Configuration")
-	fmt.Printf("* resources: %v\n", cfg.GetString("resources"))
+	resourcesPath := cfg.GetString("resources")
+	if resourcesPath == "" {
+		resourcesPath = "(embedded)"
+	}
+	fmt.Printf("* resources: %v\n", resourcesPath)
 	fmt.Printf("  * spdx/%v%v\n", cfg.GetString(configurer.SpdxFlag), licenseListVersion)
-	fmt.Printf("  * custom/%v\n", cfg.GetString(configurer.CustomFlag))
+	for _, layer := range cfg.GetStringSlice(configurer.CustomFlag) {
+		fmt.Printf("  * custom/%v\n", layer)
+	}
 	fmt.Printf("\n###### Generated on %v\n", time.Now().Format(time.RFC3339))
 	return nil
 }
 
+// fileMatches is the NDJSON line for each file in a directory scan
+type fileMatches struct {
+	File    string
+	Matches map[string][]identifier.Match `json:",omitempty"`
+	Hash    *normalizer.Digest            `json:",omitempty"`
+	Error   string                        `json:",omitempty"`
+}
+
 func findLicensesInDirectory(cfg *viper.Viper) error {
 	d := cfg.GetString(configurer.DirFlag)
+	ndjson := cfg.GetBool(configurer.NDJSONFlag)
+	incompatible := cfg.GetBool(configurer.IncompatibleFlag)
+	if ndjson && incompatible {
+		return fmt.Errorf("--%v cannot be used with --%v", configurer.NDJSONFlag, configurer.IncompatibleFlag)
+	}
 
-	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	licenseScanner, err := scanner.NewFromConfig(cfg)
 	if err != nil {
 		return err
 	}
-	if err := licenseLibrary.AddAll(); err != nil {
-		return err
-	}
 
-	options := identifier.Options{
-		ForceResult: true,
-		Enhancements: identifier.Enhancements{
-			AddNotes:       "",
-			AddTextBlocks:  true,
-			FlagAcceptable: cfg.GetBool(configurer.AcceptableFlag),
-			FlagCopyrights: cfg.GetBool(configurer.CopyrightsFlag),
-			FlagKeywords:   cfg.GetBool(configurer.KeywordsFlag),
-		},
-	}
+	// Each result is printed as soon as it is ready. Only the files by license ID are kept, for --incompatible.
+	encoder := json.NewEncoder(os.Stdout)
+	foundIn := make(map[string][]string)
+	err = licenseScanner.ScanDirectoryFunc(context.Background(), d, func(result identifier.IdentifierResults) error {
+		if ndjson {
+			line := fileMatches{File: result.File, Matches: result.Matches}
+			if cfg.GetBool(configurer.HashFlag) {
+				line.Hash = &result.Hash
+			}
+			return encoder.Encode(line)
+		}
+		printDirectoryResult(result)
+		if incompatible {
+			for id := range result.Matches {
+				foundIn[id] = append(foundIn[id], result.File)
+			}
+		}
+		return nil
+	})
 
-	results, err := identifier.IdentifyLicensesInDirectory(d, options, licenseLibrary)
-	if err != nil {
+	// With a timeout, the completed results are printed before the files which were not completed
+	var incomplete *identifier.IncompleteFilesError
+	if err != nil && !errors.As(err, &incomplete) {
 		return err
 	}
 
-	for _, result := range results {
-		if len(result.Matches) > 0 {
+	if incompatible {
+		if err := printIncompatibilities(cfg, foundIn); err != nil {
+			return err
+		}
+	}
 
-			// Print the matches by license ID in alphabetical order
-			fmt.Printf("\nFOUND LICENSE MATCHES: %v\n", result.File)
-			var found []string
-			for id := range result.Matches {
-				found = append(found, id)
-			}
-			sort.Strings(found)
-			for _, id := range found {
-				fmt.Printf("\tLicense ID:\t%v", id)
-				fmt.Println()
-				var prev identifier.Match
-				for _, m := range result.Matches[id] {
-					// Print if not same as prev
-					if m != prev {
-						fmt.Printf("\t\tbegins: %5v\tends: %5v\n", m.Begins, m.Ends)
-						prev = m
-					}
+	if incomplete != nil {
+		for _, f := range incomplete.Files {
+			if ndjson {
+				if err := encoder.Encode(fileMatches{File: f, Error: fmt.Sprintf("not completed: %v", incomplete.Err)}); err != nil {
+					return err
 				}
+			} else {
+				fmt.Printf("\nNot completed: %v\n", f)
 			}
-			fmt.Println()
+		}
+		return incomplete
+	}
+	return nil
+}
 
-			if ProjectLogger.GetLevel() >= log.INFO {
-				for _, block := range result.Blocks {
-					ProjectLogger.Infof("%v :: %v", block.Matches, block.Text)
-				}
+func printDirectoryResult(result identifier.IdentifierResults) {
+	if len(result.Matches) == 0 {
+		fmt.Printf("\nNo licenses were found: %v\n", result.File)
+		return
+	}
+
+	// Print the matches by license ID in alphabetical order
+	fmt.Printf("\nFOUND LICENSE MATCHES: %v\n", result.File)
+	var found []string
+	for id := range result.Matches {
+		found = append(found, id)
+	}
+	sort.Strings(found)
+	for _, id := range found {
+		fmt.Printf("\tLicense ID:\t%v", id)
+		fmt.Println()
+		var prev identifier.Match
+		for _, m := range result.Matches[id] {
+			// Print if not same as prev
+			if m != prev {
+				fmt.Printf("\t\tbegins: %5v\tends: %5v\n", m.Begins, m.Ends)
+				prev = m
 			}
-		} else {
-			fmt.Printf("\nNo licenses were found: %v\n", result.File)
 		}
 	}
-	return nil
+	fmt.Println()
+
+	if ProjectLogger.GetLevel() >= log.INFO {
+		for _, block := range result.Blocks {
+			ProjectLogger.Infof("%v :: %v", block.Matches, block.Text)
+		}
+	}
 }
 
 func findLicensesInFile(cfg *viper.Viper, f string) error {
@@ -224,32 +280,23 @@ func findLicensesInFile(cfg *viper.Viper, f string) error {
 	startTime := time.Now().UnixMicro()
 	ProjectLogger.Info("Looking for all licences")
 
-	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	licenseScanner, err := scanner.NewFromConfig(cfg)
 	if err != nil {
 		logScanTimeMS(startTime)
 		return err
 	}
-	if err := licenseLibrary.AddAll(); err != nil {
-		logScanTimeMS(startTime)
-		return err
-	}
-
-	options := identifier.Options{
-		ForceResult: true,
-		Enhancements: identifier.Enhancements{
-			AddNotes:       "",
-			AddTextBlocks:  true,
-			FlagAcceptable: cfg.GetBool(configurer.AcceptableFlag),
-			FlagCopyrights: cfg.GetBool(configurer.CopyrightsFlag),
-			FlagKeywords:   cfg.GetBool(configurer.KeywordsFlag),
-		},
-	}
 
-	results, err := identifier.IdentifyLicensesInFile(f, options, licenseLibrary)
+	results, err := licenseScanner.ScanFile(f)
 	if err != nil {
 		logScanTimeMS(startTime)
 		return err
 	}
+	if results.Encoding != normalizer.EncodingUTF8 {
+		ProjectLogger.Infof("Decoded %v from %v", f, results.Encoding)
+	}
+	if len(results.Windows) > 0 {
+		ProjectLogger.Infof("Identified %v windows of %v (the offsets are offsets in the file)", len(results.Windows), f)
+	}
 
 	licenseArg := cfg.GetString(configurer.LicenseFlag)
 	if len(results.Matches) > 0 {
@@ -287,7 +334,7 @@ func findLicensesInFile(cfg *viper.Viper, f string) error {
 	if licenseArg != "" {
 		// If a license is also provided, debug against that license.
 		ProjectLogger.Info("Looking for a specific license")
-		debugResults, err := debugger.DebugLicenseMatchFailure(licenseLibrary.LicenseMap[licenseArg], results.NormalizedText)
+		debugResults, err := debugger.DebugLicenseMatchFailure(licenseScanner.LicenseLibrary().LicenseMap[licenseArg], results.NormalizedText)
 		if err != nil {
 			return err
 		}
@@ -305,11 +352,99 @@ func findLicensesInFile(cfg *viper.Viper, f string) error {
 		ProjectLogger.Info("Normalized Text:")
 		ProjectLogger.Info(results.NormalizedText)
 	}
+	if cfg.GetBool(configurer.TraceFlag) {
+		if len(results.Windows) > 0 {
+			// The windows of a large file are normalized separately, and there is no text of the whole file to trace
+			ProjectLogger.Infof("No normalization trace: the file is larger than the max file size and was scanned in %v windows", len(results.Windows))
+		} else if err := printNormalizationTrace(f, results.OriginalText, licenseScanner.LicenseLibrary()); err != nil {
+			logScanTimeMS(startTime)
+			return err
+		}
+	}
+	if cfg.GetBool(configurer.IncompatibleFlag) {
+		foundIn := make(map[string][]string)
+		for id := range results.Matches {
+			foundIn[id] = []string{f}
+		}
+		if err := printIncompatibilities(cfg, foundIn); err != nil {
+			logScanTimeMS(startTime)
+			return err
+		}
+	}
 
 	logScanTimeMS(startTime)
 	return nil
 }
 
+// printNormalizationTrace prints the normalized text after each normalization step which changed it, with the edits
+// (and their position in the original text) made by the step
+func printNormalizationTrace(fileName string, text string, licenseLibrary *licenses.LicenseLibrary) error {
+	normalizedData := normalizer.NormalizationData{
+		OriginalText: text,
+		CommentStyle: licenseLibrary.CommentStyle(fileName, text),
+		Markup:       normalizer.DetectMarkup(fileName, text),
+		Trace:        &normalizer.Trace{},
+	}
+	if err := licenseLibrary.Normalize(&normalizedData); err != nil {
+		return err
+	}
+
+	ProjectLogger.Info("Normalization Trace:")
+	prev := text
+	for _, step := range normalizedData.Trace.Steps {
+		if step.NormalizedText == prev {
+			ProjectLogger.Infof("%v: no change", step.Name)
+			continue
+		}
+		ProjectLogger.Infof("%v: %v edits", step.Name, len(step.Edits))
+		for _, e := range step.Edits {
+			ProjectLogger.Infof("\t[%v-%v] %q -> %q", e.Begins, e.Ends, e.Replaced, e.Replacement)
+		}
+		ProjectLogger.Info(step.NormalizedText)
+		prev = step.NormalizedText
+	}
+	return nil
+}
+
+func checkCompatibility(cfg *viper.Viper, ids []string) error {
+	foundIn := make(map[string][]string)
+	for _, id := range ids {
+		foundIn[strings.TrimSpace(id)] = nil
+	}
+	return printIncompatibilities(cfg, foundIn)
+}
+
+// printIncompatibilities reports known incompatibilities between the license IDs and the files they were found in, if any
+func printIncompatibilities(cfg *viper.Viper, foundIn map[string][]string) error {
+	matrix, err := compatibility.NewMatrix(cfg)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(foundIn))
+	for id := range foundIn {
+		ids = append(ids, id)
+	}
+
+	incompatibilities := matrix.Check(ids)
+	if len(incompatibilities) == 0 {
+		fmt.Printf("\nNo known license incompatibilities were found\n")
+		return nil
+	}
+
+	fmt.Printf("\nINCOMPATIBLE LICENSES:\n")
+	for _, incompatibility := range incompatibilities {
+		fmt.Printf("\t%v and %v:\t%v\n", incompatibility.License, incompatibility.Conflict, incompatibility.Reason)
+		for _, id := range []string{incompatibility.License, incompatibility.Conflict} {
+			for _, f := range foundIn[id] {
+				fmt.Printf("\t\t%v:\t%v\n", id, f)
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
 func notGlobalInit(c *cobra.Command) {
 	// Add configurer flag definitions, shared with API, added to CLI flags here.
 	configurer.AddDefaultFlags(c.Flags())
@@ -73,6 +73,16 @@ func Test_CLI_file(t *testing.T) {
 	}
 }
 
+func Test_CLI_file_trace_large_file(t *testing.T) {
+	t.Parallel()
+	// the file is scanned in windows, so there is no text of the whole file to trace
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"-f", "../testdata/addAll/input/text/0BSD.txt", "--trace", "--maxFileSize", "200", "--largeFiles", "headTail"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+}
+
 func Test_CLI_addAll_Bogus(t *testing.T) {
 	t.Parallel()
 	cmd := NewRootCmd()
@@ -193,3 +203,26 @@ func Test_CLI_list_spdx(t *testing.T) {
 		t.Fatalf("Expected nil err for valid --spdx dir and --list got: %v", err)
 	}
 }
+
+func Test_CLI_checkIDs(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{
+		"--checkIDs", "GPL-2.0-only,Apache-2.0",
+	})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected nil err for valid --checkIDs got: %v", err)
+	}
+}
+
+func Test_CLI_compatibility_not_found(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{
+		"--compatibility", "bogus",
+		"--checkIDs", "GPL-2.0-only,Apache-2.0",
+	})
+	if err := cmd.Execute(); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected ErrNotExist got: %v", err)
+	}
+}
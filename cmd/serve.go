@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/mrutkows/sbom-utility/log"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+
+	"github.com/IBM/license-scanner/api/scanner"
+	"github.com/IBM/license-scanner/api/server"
+	"github.com/IBM/license-scanner/api/server/scannerpb"
+	"github.com/IBM/license-scanner/configurer"
+)
+
+func NewServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve license scanning over HTTP",
+		Long: `
+Load the license library once and serve license scanning over HTTP.
+
+    POST /v1/scan          ScanSpecs JSON
+    POST /v1/scan/text     license text
+    POST /v1/scan/file     multipart form "file" fields (files, or zip/jar/tar/tar.gz archives)
+    GET  /v1/licenses      list the licenses and exceptions
+    GET  /v1/licenses/{id} a license with its template text
+
+With --grpcAddr, the LicenseScanner gRPC service (api/server/scannerpb/scanner.proto)
+is also served, including the ScanStream streaming batch API.
+
+Example usage:
+
+    $ license-scanner serve --addr localhost:8080 --grpcAddr localhost:9090
+		`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := configurer.InitConfig(cmd.Flags())
+			if err != nil {
+				ProjectLogger.Error(err)
+				return err
+			}
+
+			if cfg.GetBool(configurer.DebugFlag) {
+				ProjectLogger.SetLevel(log.DEBUG)
+			}
+			ProjectLogger.SetQuietMode(cfg.GetBool(configurer.QuietFlag))
+
+			// The library is loaded once and the scanner is shared by the HTTP and gRPC servers
+			licenseScanner, err := scanner.NewFromConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			// When a server fails, or the command context is done, the other server is stopped
+			servers, ctx := errgroup.WithContext(cmd.Context())
+
+			var grpcServer *grpc.Server
+			if grpcAddr := cfg.GetString(configurer.GRPCAddrFlag); grpcAddr != "" {
+				lis, err := net.Listen("tcp", grpcAddr)
+				if err != nil {
+					return err
+				}
+				grpcServer = grpc.NewServer()
+				scannerpb.RegisterLicenseScannerServer(grpcServer, server.NewGRPCServerWithScanner(licenseScanner))
+				ProjectLogger.Infof("Serving gRPC license scanning on %v", grpcAddr)
+				servers.Go(func() error {
+					return grpcServer.Serve(lis)
+				})
+			}
+
+			addr := cfg.GetString(configurer.AddrFlag)
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           server.NewServerWithScanner(licenseScanner),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			ProjectLogger.Infof("Serving license scanning on %v", addr)
+			servers.Go(func() error {
+				if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			})
+
+			servers.Go(func() error {
+				<-ctx.Done()
+				if grpcServer != nil {
+					grpcServer.GracefulStop()
+				}
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				return srv.Shutdown(shutdownCtx)
+			})
+
+			return servers.Wait()
+		},
+	}
+	configurer.AddDefaultFlags(cmd.Flags())
+	cmd.Flags().String(configurer.AddrFlag, ":8080", "Address to serve HTTP on")
+	cmd.Flags().String(configurer.GRPCAddrFlag, "", "Address to serve gRPC on (no gRPC server if not set)")
+	return cmd
+}
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package cmd
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_CLI_serve_addr_in_use(t *testing.T) {
+	t.Parallel()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	// The HTTP server fails while the gRPC server is serving, so both are stopped and the error is returned
+	cmd := NewServeCmd()
+	cmd.SetArgs([]string{"--quiet", "--addr", lis.Addr().String(), "--grpcAddr", "127.0.0.1:0"})
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Execute()
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error serving on an address in use")
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("serve did not return when the HTTP server failed")
+	}
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package compatibility
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/resources"
+)
+
+const (
+	compatibilityDir  = "compatibility"
+	CompatibilityJSON = "compatibility.json"
+	withOperator      = " WITH "
+)
+
+// Matrix holds the known incompatibilities between licenses
+type Matrix struct {
+	// Aliases map deprecated IDs to a current ID or an "ID WITH exception" expression
+	Aliases map[string]string `json:"aliases"`
+	// OrLater maps each -or-later ID to the versions a licensee may choose from
+	OrLater map[string][]string `json:"or_later"`
+	// Groups name sets of IDs which can be used in rules in place of the IDs
+	Groups map[string][]string `json:"groups"`
+	// Incompatibilities are the rules used to check a combination of licenses
+	Incompatibilities []Rule `json:"incompatibilities"`
+}
+
+// Rule declares that any of Licenses cannot be distributed together with any of Conflicts,
+// unless one of the Exceptions is attached with WITH to either side.
+type Rule struct {
+	Licenses   []string `json:"licenses"`
+	Conflicts  []string `json:"conflicts"`
+	Exceptions []string `json:"exceptions"`
+	Reason     string   `json:"reason"`
+}
+
+// Incompatibility is a reported pair of licenses which cannot be distributed together
+type Incompatibility struct {
+	License  string
+	Conflict string
+	Reason   string
+}
+
+// license is an ID with any WITH exceptions
+type license struct {
+	ID         string
+	Exceptions []string
+}
+
+// ReadMatrixJSON unmarshalls the json bytes into Matrix
+func ReadMatrixJSON(fileContents []byte) (*Matrix, error) {
+	var m Matrix
+	if err := json.Unmarshal(fileContents, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// NewMatrix reads the compatibility matrix selected by the compatibility flag from the resources
+func NewMatrix(config *viper.Viper) (*Matrix, error) {
+	if config == nil {
+		cfg, err := configurer.InitConfig(nil)
+		if err != nil {
+			return nil, err
+		}
+		config = cfg
+	}
+
+	resourcesPath := config.GetString("resources")
+	name := path.Join(compatibilityDir, config.GetString(configurer.CompatibilityFlag), CompatibilityJSON)
+	f := path.Join(resourcesPath, name)
+	b, err := fs.ReadFile(resources.FS(resourcesPath), name)
+	if err != nil {
+		return nil, fmt.Errorf("read compatibility matrix from %v error: %w", f, err)
+	}
+	m, err := ReadMatrixJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal compatibility matrix from %v error: %w", f, err)
+	}
+	return m, nil
+}
+
+// Check reports the known incompatibilities between each pair of the given license IDs.
+// IDs may be SPDX expressions of the form "ID WITH exception".
+func (m *Matrix) Check(ids []string) []Incompatibility {
+	var ret []Incompatibility
+
+	unique := uniqueSorted(ids)
+	for i := range unique {
+		for j := i + 1; j < len(unique); j++ {
+			if reason, ok := m.conflict(unique[i], unique[j]); ok {
+				ret = append(ret, Incompatibility{License: unique[i], Conflict: unique[j], Reason: reason})
+			}
+		}
+	}
+	return ret
+}
+
+// conflict returns the reason the two expressions are incompatible.
+// If either side is an -or-later license, the pair only conflicts when every version choice conflicts.
+func (m *Matrix) conflict(a string, b string) (reason string, found bool) {
+	for _, la := range m.versions(m.parse(a)) {
+		for _, lb := range m.versions(m.parse(b)) {
+			r, ok := m.ruleConflict(la, lb)
+			if !ok {
+				return "", false // a compatible version choice exists
+			}
+			reason = r
+		}
+	}
+	return reason, true
+}
+
+// ruleConflict finds the first rule in either direction which is not lifted by an exception
+func (m *Matrix) ruleConflict(a license, b license) (string, bool) {
+	for _, rule := range m.Incompatibilities {
+		if rule.lifted(a) || rule.lifted(b) {
+			continue
+		}
+		licenses := m.expand(rule.Licenses)
+		conflicts := m.expand(rule.Conflicts)
+		if (licenses[a.ID] && conflicts[b.ID]) || (licenses[b.ID] && conflicts[a.ID]) {
+			return rule.Reason, true
+		}
+	}
+	return "", false
+}
+
+func (r Rule) lifted(l license) bool {
+	for _, e := range l.Exceptions {
+		for _, re := range r.Exceptions {
+			if e == re {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parse splits "ID WITH exception" and resolves deprecated aliases
+func (m *Matrix) parse(expression string) license {
+	parts := strings.Split(strings.TrimSpace(expression), withOperator)
+	l := license{ID: strings.TrimSpace(parts[0])}
+	for _, e := range parts[1:] {
+		l.Exceptions = append(l.Exceptions, strings.TrimSpace(e))
+	}
+
+	if alias, ok := m.Aliases[l.ID]; ok {
+		resolved := m.parse(alias)
+		l.ID = resolved.ID
+		l.Exceptions = append(l.Exceptions, resolved.Exceptions...)
+	}
+	return l
+}
+
+// versions returns the license choices a licensee has for an -or-later license (keeping any exceptions)
+func (m *Matrix) versions(l license) []license {
+	ids, ok := m.OrLater[l.ID]
+	if !ok {
+		return []license{l}
+	}
+	ret := make([]license, 0, len(ids))
+	for _, id := range ids {
+		ret = append(ret, license{ID: id, Exceptions: l.Exceptions})
+	}
+	return ret
+}
+
+// expand returns the set of IDs, replacing group names with the group members
+func (m *Matrix) expand(names []string) map[string]bool {
+	ret := make(map[string]bool)
+	for _, name := range names {
+		if members, ok := m.Groups[name]; ok {
+			for _, id := range members {
+				ret[id] = true
+			}
+		} else {
+			ret[name] = true
+		}
+	}
+	return ret
+}
+
+func uniqueSorted(ids []string) []string {
+	seen := make(map[string]bool)
+	var ret []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ret = append(ret, id)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
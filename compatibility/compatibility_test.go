@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package compatibility
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/configurer"
+)
+
+func TestMatrix_Check(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewMatrix(nil)
+	if err != nil {
+		t.Fatalf("NewMatrix() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ids  []string
+		want [][2]string
+	}{
+		{
+			name: "permissive only",
+			ids:  []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+		},
+		{
+			name: "GPL-2.0-only with Apache-2.0",
+			ids:  []string{"GPL-2.0-only", "Apache-2.0"},
+			want: [][2]string{{"Apache-2.0", "GPL-2.0-only"}},
+		},
+		{
+			name: "deprecated GPL-2.0 with Apache-2.0",
+			ids:  []string{"GPL-2.0", "Apache-2.0"},
+			want: [][2]string{{"Apache-2.0", "GPL-2.0"}},
+		},
+		{
+			name: "GPL-2.0-or-later can choose GPL-3.0 to combine with Apache-2.0",
+			ids:  []string{"GPL-2.0-or-later", "Apache-2.0"},
+		},
+		{
+			name: "GPL-2.0-or-later cannot choose around CDDL-1.0",
+			ids:  []string{"GPL-2.0-or-later", "CDDL-1.0"},
+			want: [][2]string{{"CDDL-1.0", "GPL-2.0-or-later"}},
+		},
+		{
+			name: "GPL-2.0-only with GPL-3.0-only",
+			ids:  []string{"GPL-3.0-only", "GPL-2.0-only", "MIT"},
+			want: [][2]string{{"GPL-2.0-only", "GPL-3.0-only"}},
+		},
+		{
+			name: "Classpath exception lifts the conflict",
+			ids:  []string{"GPL-2.0-only WITH Classpath-exception-2.0", "Apache-2.0"},
+		},
+		{
+			name: "LLVM exception on the other side lifts the conflict",
+			ids:  []string{"GPL-2.0-only", "Apache-2.0 WITH LLVM-exception"},
+		},
+		{
+			name: "deprecated ID with exception lifts the conflict",
+			ids:  []string{"GPL-2.0-with-classpath-exception", "Apache-2.0"},
+		},
+		{
+			name: "proprietary with AGPL",
+			ids:  []string{"LicenseRef-Proprietary", "AGPL-3.0-or-later"},
+			want: [][2]string{{"AGPL-3.0-or-later", "LicenseRef-Proprietary"}},
+		},
+		{
+			name: "proprietary with GCC runtime exception",
+			ids:  []string{"LicenseRef-Proprietary", "GPL-3.0-only WITH GCC-exception-3.1"},
+		},
+		{
+			name: "duplicates and blanks are ignored",
+			ids:  []string{"Apache-2.0", " GPL-2.0-only", "Apache-2.0", ""},
+			want: [][2]string{{"Apache-2.0", "GPL-2.0-only"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got [][2]string
+			for _, incompatibility := range m.Check(tt.ids) {
+				if incompatibility.Reason == "" {
+					t.Errorf("Check() returned an incompatibility without a reason: %+v", incompatibility)
+				}
+				got = append(got, [2]string{incompatibility.License, incompatibility.Conflict})
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Didn't get expected incompatibilities: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestReadMatrixJSON(t *testing.T) {
+	t.Parallel()
+
+	m, err := ReadMatrixJSON([]byte(`
+{
+  "groups": {"copyleft": ["Team-Copyleft-1.0"]},
+  "incompatibilities": [
+    {"licenses": ["copyleft"], "conflicts": ["LicenseRef-Internal"], "reason": "team policy"}
+  ]
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := m.Check([]string{"LicenseRef-Internal", "Team-Copyleft-1.0"})
+	want := []Incompatibility{{License: "LicenseRef-Internal", Conflict: "Team-Copyleft-1.0", Reason: "team policy"}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Didn't get expected incompatibilities: (-want, +got): %v", d)
+	}
+
+	if _, err := ReadMatrixJSON([]byte(`{"groups": "not a map"}`)); err == nil {
+		t.Error("expected an unmarshal error")
+	}
+}
+
+func TestNewMatrix_embedded(t *testing.T) {
+	t.Parallel()
+
+	config, err := configurer.InitConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set("resources", "") // no resources dir uses the embedded resources
+
+	m, err := NewMatrix(config)
+	if err != nil {
+		t.Fatalf("NewMatrix() error = %v", err)
+	}
+	if len(m.Check([]string{"GPL-2.0-only", "Apache-2.0"})) != 1 {
+		t.Error("expected the embedded matrix to report GPL-2.0-only with Apache-2.0")
+	}
+}
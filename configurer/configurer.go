@@ -3,6 +3,7 @@
 package configurer
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -18,6 +19,7 @@ const (
 	AcceptableFlag = "acceptable"
 	CopyrightsFlag = "copyrights"
 	NormalizedFlag = "normalized"
+	TraceFlag      = "trace"
 	HashFlag       = "hash"
 	KeywordsFlag   = "keywords"
 	ListFlag       = "list"
@@ -32,6 +34,22 @@ const (
 	ConfigNameFlag = "configName"
 	SpdxFlag       = "spdx"
 	CustomFlag     = "custom"
+
+	CompatibilityFlag = "compatibility"
+	IncompatibleFlag  = "incompatible"
+	CheckIDsFlag      = "checkIDs"
+	LibraryCacheFlag  = "libraryCache"
+	ResultsCacheFlag  = "resultsCache"
+	KnownHashesFlag   = "knownHashes"
+	TimeoutFlag       = "timeout"
+	FileTimeoutFlag   = "fileTimeout"
+	MaxFileSizeFlag   = "maxFileSize"
+	LargeFilesFlag    = "largeFiles"
+	ChunkOverlapFlag  = "chunkOverlap"
+	NDJSONFlag        = "ndjson"
+	WorkersFlag       = "workers"
+	AddrFlag          = "addr"
+	GRPCAddrFlag      = "grpcAddr"
 )
 
 var (
@@ -46,7 +64,7 @@ func InitConfig(flags *pflag.FlagSet) (*viper.Viper, error) {
 	newViper := viper.New()
 	newViper.AutomaticEnv()
 
-	newViper.SetDefault("resources", path.Join(projectRoot, "resources"))
+	// There is no default resources path. Without a configured resources path, the embedded resources are used.
 	newViper.SetDefault("configName", "config")
 
 	if flags != nil {
@@ -84,7 +102,11 @@ func InitConfig(flags *pflag.FlagSet) (*viper.Viper, error) {
 
 	err := newViper.MergeInConfig()
 	if err != nil {
-		return nil, fmt.Errorf("MergeInConfig err: %w", err)
+		// A config file is optional unless a location was given
+		var notFound viper.ConfigFileNotFoundError
+		if configFrom != "" || configPath != "" || !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("MergeInConfig err: %w", err)
+		}
 	}
 
 	// If we didn't get a resources flag, then relative config file resources need to be relative to the config file
@@ -124,18 +146,32 @@ func AddDefaultFlags(flagSet *pflag.FlagSet) {
 	flagSet.BoolP(DebugFlag, "d", false, "Enable debug logging")
 	flagSet.BoolP(QuietFlag, "q", false, "Set logging to quiet")
 	flagSet.String(DirFlag, "", "A directory in which to identify licenses")
+	flagSet.Bool(NDJSONFlag, false, "Write a JSON line for each file as it is scanned in a directory (NDJSON)")
 	flagSet.StringP(FileFlag, "f", "", "A file in which to identify licenses")
 	flagSet.BoolP(AcceptableFlag, "g", false, "Flag acceptable")
 	flagSet.BoolP(KeywordsFlag, "k", false, "Flag keywords")
 	flagSet.BoolP(CopyrightsFlag, "c", false, "Flag copyrights")
 	flagSet.BoolP(NormalizedFlag, "n", false, "Flag normalized")
+	flagSet.Bool(TraceFlag, false, "Print the normalized text after each normalization step for the file")
 	flagSet.BoolP(HashFlag, "x", false, "Output file hash")
 	flagSet.StringP(LicenseFlag, "l", "", "Display match debugging for the given license")
 	flagSet.StringP(AddPatternFlag, "a", "", "Add a new license pattern to the library, from SPDX")
 	flagSet.Bool(ListFlag, false, "List the license templates to be used")
 	flagSet.String(AddAllFlag, "", "Add the licenses from SPDX unzipped release")
+	flagSet.Bool(KnownHashesFlag, false, "Write the known hashes of the SPDX license texts (testdata) for the --spdx templates")
 	flagSet.String(ConfigPathFlag, "", "Path to any config files")
 	flagSet.String(ConfigNameFlag, "config", "Base name for config file")
 	flagSet.String(SpdxFlag, "default", "SPDX templates to use")
-	flagSet.String(CustomFlag, "default", "Custom templates to use")
+	flagSet.StringSlice(CustomFlag, []string{"default"}, "Custom template layers to use (later layers add to and override earlier layers)")
+	flagSet.String(CompatibilityFlag, "default", "Compatibility matrix to use")
+	flagSet.Bool(IncompatibleFlag, false, "Flag known incompatibilities between the licenses found")
+	flagSet.String(CheckIDsFlag, "", "Check a comma-separated list of license IDs for known incompatibilities")
+	flagSet.String(LibraryCacheFlag, "", "Cache file for the compiled license library (rebuilt when resources change)")
+	flagSet.String(ResultsCacheFlag, "", "Cache directory for scan results by normalized text (not reused when resources change)")
+	flagSet.Duration(TimeoutFlag, 0, "Deadline for a scan, or for each request when serving (e.g. 30s, no deadline if 0)")
+	flagSet.Duration(FileTimeoutFlag, 0, "Deadline for each file in a directory scan (e.g. 5s, no deadline if 0)")
+	flagSet.Int64(MaxFileSizeFlag, 0, "Largest file in bytes identified as a whole (10000000 if 0)")
+	flagSet.String(LargeFilesFlag, "reject", "Policy for a larger file: reject, headTail (identify the first and last maxFileSize/2 bytes) or chunks")
+	flagSet.Int64(ChunkOverlapFlag, 0, "Overlap in bytes of the chunks of a larger file with --largeFiles chunks (200000 if 0)")
+	flagSet.Int(WorkersFlag, 0, "Workers identifying files and matching patterns (GOMAXPROCS if 0)")
 }
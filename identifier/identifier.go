@@ -3,6 +3,8 @@
 package identifier
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
@@ -12,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mrutkows/sbom-utility/log"
 	"golang.org/x/exp/slices"
@@ -21,6 +24,23 @@ import (
 	"github.com/IBM/license-scanner/normalizer"
 )
 
+// MaxFileSize is the default largest file, in bytes, that is read and identified as a whole (see Options.MaxFileSize)
+const MaxFileSize = 10000000
+
+// DefaultChunkOverlap is the default overlap, in bytes, of the chunks of a large file (see Options.ChunkOverlap).
+// It is longer than the longest license text, also in UTF-16.
+const DefaultChunkOverlap = 200000
+
+// Policies for a file larger than the Options.MaxFileSize
+const (
+	// LargeFilesReject returns an error for the file
+	LargeFilesReject = "reject"
+	// LargeFilesHeadTail identifies the licenses in the first and in the last MaxFileSize/2 bytes of the file
+	LargeFilesHeadTail = "headTail"
+	// LargeFilesChunks identifies the licenses in chunks of MaxFileSize bytes, which overlap by the ChunkOverlap
+	LargeFilesChunks = "chunks"
+)
+
 var (
 	Logger     = log.NewLogger(log.INFO)
 	nonAlphaRE = regexp.MustCompile(`^[^A-Za-z0-9]*$`)
@@ -30,6 +50,45 @@ type Options struct {
 	ForceResult  bool
 	OmitBlocks   bool
 	Enhancements Enhancements
+	// FileTimeout is the deadline for identifying each file (no deadline if zero).
+	// In a directory, a file which times out is reported as incomplete and the other files are still identified.
+	FileTimeout time.Duration
+	// Scheduler limits the workers identifying files and matching patterns.
+	// If nil, a default scheduler with GOMAXPROCS workers is shared by all the scans without one.
+	Scheduler *Scheduler
+	// Pipeline normalizes the input texts, e.g. the license library Pipeline() with a step to remove generated headers.
+	// If nil, the pipeline of the license library is used, which also normalizes the license patterns.
+	Pipeline normalizer.Pipeline
+	// MaxFileSize is the largest file, in bytes, which is identified as a whole (the MaxFileSize constant if zero).
+	MaxFileSize int64
+	// LargeFiles is the policy for a larger file: LargeFilesReject (the default if empty), LargeFilesHeadTail or LargeFilesChunks.
+	LargeFiles string
+	// ChunkOverlap is the overlap, in bytes, of the chunks of a large file (DefaultChunkOverlap if zero).
+	// It must be less than the MaxFileSize. A license text longer than the overlap may not be found where two chunks overlap.
+	ChunkOverlap int64
+}
+
+// Normalize normalizes the input with the pipeline of the options, or with the pipeline of the license library
+func (o Options) Normalize(normalizedData *normalizer.NormalizationData, licenseLibrary *licenses.LicenseLibrary) error {
+	if o.Pipeline == nil {
+		return licenseLibrary.Normalize(normalizedData)
+	}
+	return o.Pipeline.Normalize(normalizedData)
+}
+
+// IncompleteFilesError is returned with the completed results when some files were not identified,
+// because the context was done or a file timed out
+type IncompleteFilesError struct {
+	Files []string
+	Err   error
+}
+
+func (e *IncompleteFilesError) Error() string {
+	return fmt.Sprintf("%v files not completed: %v", len(e.Files), e.Err)
+}
+
+func (e *IncompleteFilesError) Unwrap() error {
+	return e.Err
 }
 
 type licenseMatch struct {
@@ -59,6 +118,18 @@ type IdentifierResults struct {
 	AcceptablePatternMatches []PatternMatch
 	KeywordMatches           []PatternMatch
 	CopyRightStatements      []PatternMatch
+	// Encoding is the detected encoding of the File (see normalizer.DecodeText)
+	Encoding string
+	// ByteOffsets maps the offsets in the OriginalText to the offsets in the File, when it is not UTF-8 (see FileOffset)
+	ByteOffsets []int
+	// Windows are the ranges of bytes of a File larger than the Options.MaxFileSize which were identified (see Options.LargeFiles).
+	// The offsets of the matches are then offsets in the File, and there is no text, hash or blocks for the whole File.
+	Windows []Match
+}
+
+// FileOffset returns the offset in the File of the character at an offset in the OriginalText, e.g. Match.Begins
+func (r IdentifierResults) FileOffset(textOffset int) int {
+	return normalizer.FileOffset(r.ByteOffsets, textOffset)
 }
 
 type Block struct {
@@ -67,9 +138,29 @@ type Block struct {
 }
 
 func Identify(options Options, licenseLibrary *licenses.LicenseLibrary, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
+	return IdentifyContext(context.Background(), options, licenseLibrary, normalizedData)
+}
+
+// IdentifyContext identifies the licenses in the normalized data. It stops and returns the context error when the context is done.
+func IdentifyContext(ctx context.Context, options Options, licenseLibrary *licenses.LicenseLibrary, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentifierResults{}, err
+	}
+
+	// a known license text (by hash) is identified without matching the patterns
+	// the known license IDs are the final results (mutators were already applied)
+	knownIDs, known := licenseLibrary.KnownHashes[normalizedData.Hash.Sha256]
+	known = known && normalizedData.Hash.Sha256 != ""
+
 	// find the licenses in the normalized text and return a list of SPDX IDs
 	// in case of an error, return as much as we have along with an error
-	licenseResults, err := findAllLicensesInNormalizedData(licenseLibrary, normalizedData)
+	var licenseResults IdentifierResults
+	var err error
+	if known {
+		licenseResults, err = findKnownLicensesInNormalizedData(knownIDs, normalizedData)
+	} else {
+		licenseResults, err = findAllLicensesInNormalizedData(ctx, options.scheduler(), licenseLibrary, normalizedData)
+	}
 	if err != nil {
 		return IdentifierResults{}, err
 	}
@@ -78,8 +169,10 @@ func Identify(options Options, licenseLibrary *licenses.LicenseLibrary, normaliz
 		return IdentifierResults{}, err
 	}
 
-	if err := applyMutatorLicenses(licenseLibrary.LicenseMap, &licenseResults); err != nil {
-		return IdentifierResults{}, err
+	if !known {
+		if err := applyMutatorLicenses(licenseLibrary.LicenseMap, &licenseResults); err != nil {
+			return IdentifierResults{}, err
+		}
 	}
 
 	if options.OmitBlocks {
@@ -90,87 +183,286 @@ func Identify(options Options, licenseLibrary *licenses.LicenseLibrary, normaliz
 }
 
 func IdentifyLicensesInString(input string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	return IdentifyLicensesInStringContext(context.Background(), input, options, licenseLibrary)
+}
+
+// IdentifyLicensesInStringContext normalizes the input and identifies its licenses until the context is done
+func IdentifyLicensesInStringContext(ctx context.Context, input string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	return identifyLicensesInText(ctx, input, "", options, licenseLibrary)
+}
+
+// identifyLicensesInText normalizes the input, with the comment style of the file name or shebang, and identifies its licenses
+func identifyLicensesInText(ctx context.Context, input string, fileName string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
 	// instantiate normalizedData with the input license text
 	normalizedData := normalizer.NormalizationData{
 		OriginalText: input,
+		CommentStyle: licenseLibrary.CommentStyle(fileName, input),
+		Markup:       normalizer.DetectMarkup(fileName, input),
 	}
 
 	// normalize the input license text
-	if err := normalizedData.NormalizeText(); err != nil {
+	if err := options.Normalize(&normalizedData, licenseLibrary); err != nil {
 		return IdentifierResults{}, err
 	}
 
-	return Identify(options, licenseLibrary, normalizedData)
+	return IdentifyContext(ctx, options, licenseLibrary, normalizedData)
 }
 
 func IdentifyLicensesInFile(filePath string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	return IdentifyLicensesInFileContext(context.Background(), filePath, options, licenseLibrary)
+}
+
+// IdentifyLicensesInFileContext identifies the licenses in a file until the context is done or the options.FileTimeout expires
+func IdentifyLicensesInFileContext(ctx context.Context, filePath string, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	if options.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.FileTimeout)
+		defer cancel()
+	}
+
+	maxFileSize, err := options.maxFileSize()
+	if err != nil {
+		return IdentifierResults{}, err
+	}
+
 	fi, err := os.Stat(filePath)
 	if err != nil {
 		return IdentifierResults{}, err
 	}
-	if fi.Size() > 1000000 {
-		return IdentifierResults{}, fmt.Errorf("file too large (%v > 1000000)", fi.Size())
+	if fi.Size() > maxFileSize {
+		if options.LargeFiles == "" || options.LargeFiles == LargeFilesReject {
+			return IdentifierResults{}, fmt.Errorf("file too large (%v > %v)", fi.Size(), maxFileSize)
+		}
+		return identifyLicensesInWindows(ctx, filePath, fi.Size(), maxFileSize, options, licenseLibrary)
 	}
 
 	b, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return IdentifierResults{}, err
 	}
-	input := string(b)
+	// UTF-16 and single byte encoded files are transcoded to UTF-8
+	decoded := normalizer.DecodeText(b)
 
-	result, err := IdentifyLicensesInString(input, options, licenseLibrary)
+	result, err := identifyLicensesInText(ctx, decoded.Text, filePath, options, licenseLibrary)
 	result.File = filePath
+	result.Encoding = decoded.Encoding
+	result.ByteOffsets = decoded.ByteOffsets
 	return result, err
 }
 
-func IdentifyLicensesInDirectory(dirPath string, options Options, licenseLibrary *licenses.LicenseLibrary) (ret []IdentifierResults, err error) {
-	var lfs []string
+// maxFileSize returns the largest file identified as a whole, checking the large files policy
+func (o Options) maxFileSize() (int64, error) {
+	maxFileSize := o.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = MaxFileSize
+	}
+	switch o.LargeFiles {
+	case "", LargeFilesReject, LargeFilesHeadTail:
+	case LargeFilesChunks:
+		if overlap := o.chunkOverlap(); overlap >= maxFileSize {
+			return 0, fmt.Errorf("chunk overlap %v must be less than the max file size %v", overlap, maxFileSize)
+		}
+	default:
+		return 0, fmt.Errorf("unknown large files policy %q (expected %q, %q or %q)", o.LargeFiles, LargeFilesReject, LargeFilesHeadTail, LargeFilesChunks)
+	}
+	return maxFileSize, nil
+}
+
+func (o Options) chunkOverlap() int64 {
+	if o.ChunkOverlap <= 0 {
+		return DefaultChunkOverlap
+	}
+	return o.ChunkOverlap
+}
+
+// window is a range of bytes of a large file [begins, ends) whose matches beginning before ownEnds are kept
+// (a match beginning in the overlap with the next chunk is kept from the next chunk)
+type window struct {
+	begins, ends, ownEnds int64
+}
+
+// windows returns the windows of a file larger than the max file size, starting at even offsets (for UTF-16)
+func (o Options) windows(size int64, maxFileSize int64) []window {
+	if o.LargeFiles == LargeFilesHeadTail {
+		half := maxFileSize / 2
+		tail := (size - half) &^ 1
+		return []window{{begins: 0, ends: half, ownEnds: half}, {begins: tail, ends: size, ownEnds: size}}
+	}
+	step := (maxFileSize - o.chunkOverlap()) &^ 1
+	if step <= 0 {
+		step = 2
+	}
+	var windows []window
+	for begins := int64(0); ; begins += step {
+		w := window{begins: begins, ends: begins + maxFileSize, ownEnds: begins + step}
+		if w.ends >= size {
+			w.ends, w.ownEnds = size, size
+			return append(windows, w)
+		}
+		windows = append(windows, w)
+	}
+}
+
+// identifyLicensesInWindows identifies the licenses in the windows of a file larger than the max file size (see Options.LargeFiles).
+// A window of a UTF-16 file is decoded as UTF-16, and the encoding of the other windows is detected by window (see normalizer.DecodePart).
+// The matches are merged with their offsets in the file.
+func identifyLicensesInWindows(ctx context.Context, filePath string, size int64, maxFileSize int64, options Options, licenseLibrary *licenses.LicenseLibrary) (IdentifierResults, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return IdentifierResults{}, err
+	}
+	defer f.Close()
+
+	ret := IdentifierResults{
+		File:    filePath,
+		Matches: make(map[string][]Match),
+	}
+	buf := make([]byte, maxFileSize)
+	for i, w := range options.windows(size, maxFileSize) {
+		if err := ctx.Err(); err != nil {
+			return ret, err
+		}
+		b := buf[:w.ends-w.begins]
+		// a full read of the last window may also return io.EOF
+		if n, err := f.ReadAt(b, w.begins); n < len(b) {
+			return ret, err
+		}
+		if i == 0 {
+			ret.Encoding = normalizer.DetectEncoding(b)
+		}
+		decoded := normalizer.DecodePart(b, ret.Encoding)
+		// a single byte encoded file may only be detected by a window after its ASCII start
+		ret.Encoding = decoded.Encoding
 
-	if err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		result, err := identifyLicensesInText(ctx, decoded.Text, filePath, options, licenseLibrary)
 		if err != nil {
-			fmt.Printf("prevent panic by handling failure accessing a path %q: %v\n", path, err)
-			return err
+			return ret, err
 		}
-		if !d.IsDir() {
-			info, _ := d.Info()
-			if info.Size() > 0 {
-				lfs = append(lfs, path)
+
+		// the offsets in the text of the window are offsets in the file from the start of the window
+		fileOffset := func(textOffset int) int {
+			return int(w.begins) + decoded.FileOffset(textOffset)
+		}
+		owned := func(textOffset int) bool {
+			return int64(fileOffset(textOffset)) < w.ownEnds
+		}
+		for id, matches := range result.Matches {
+			for _, m := range matches {
+				if owned(m.Begins) {
+					ret.Matches[id] = append(ret.Matches[id], Match{Begins: fileOffset(m.Begins), Ends: fileOffset(m.Ends)})
+				}
 			}
 		}
-		return nil
-	}); err != nil {
-		fmt.Printf("error walking the path %v: %v\n", dirPath, err)
-		return nil, err
+		patternMatches := func(pms []PatternMatch) (kept []PatternMatch) {
+			for _, pm := range pms {
+				if owned(pm.Begins) {
+					kept = append(kept, PatternMatch{Text: pm.Text, Begins: fileOffset(pm.Begins), Ends: fileOffset(pm.Ends)})
+				}
+			}
+			return kept
+		}
+		ret.AcceptablePatternMatches = append(ret.AcceptablePatternMatches, patternMatches(result.AcceptablePatternMatches)...)
+		ret.KeywordMatches = append(ret.KeywordMatches, patternMatches(result.KeywordMatches)...)
+		ret.CopyRightStatements = append(ret.CopyRightStatements, patternMatches(result.CopyRightStatements)...)
+		ret.Notes = result.Notes
+		ret.Windows = append(ret.Windows, Match{Begins: int(w.begins), Ends: int(w.ends) - 1})
 	}
+	return ret, nil
+}
 
-	// errGroup to do the work in parallel until error
-	workers := errgroup.Group{}
-	workers.SetLimit(10)
-	ch := make(chan IdentifierResults, 10)
+func IdentifyLicensesInDirectory(dirPath string, options Options, licenseLibrary *licenses.LicenseLibrary) (ret []IdentifierResults, err error) {
+	return IdentifyLicensesInDirectoryContext(context.Background(), dirPath, options, licenseLibrary)
+}
 
-	// WaitGroup to know when we have all the results
+// IdentifyLicensesInDirectoryContext identifies the licenses in each file in a directory tree until the context is done.
+// Files which were not identified before the context was done, or before their options.FileTimeout expired,
+// are reported in an *IncompleteFilesError which is returned with the completed results
+// (if the walk of the directory tree was not completed, the directory is also reported).
+// All the results are kept in memory. Use IdentifyLicensesInDirectoryFunc to handle each result as it is ready.
+func IdentifyLicensesInDirectoryContext(ctx context.Context, dirPath string, options Options, licenseLibrary *licenses.LicenseLibrary) (ret []IdentifierResults, err error) {
+	err = IdentifyLicensesInDirectoryFunc(ctx, dirPath, options, licenseLibrary, func(ir IdentifierResults) error {
+		ret = append(ret, ir)
+		return nil
+	})
+	return ret, err
+}
+
+// ResultFunc handles the result of a file. Returning an error stops the scan and the error is returned.
+type ResultFunc func(IdentifierResults) error
+
+// IdentifyLicensesInDirectoryFunc identifies the licenses in each file in a directory tree and calls fn with the result
+// of each file as soon as it is ready (in completion order, one call at a time), so the results are not kept in memory.
+// Files are identified while the tree is walked. Incomplete files are reported as in IdentifyLicensesInDirectoryContext.
+func IdentifyLicensesInDirectoryFunc(ctx context.Context, dirPath string, options Options, licenseLibrary *licenses.LicenseLibrary, fn ResultFunc) (err error) {
+	// fn can stop the workers with an error
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	// errGroup to do the work in parallel until error (an error cancels the other workers)
+	// the number of files in progress is limited by the scheduler
+	scheduler := options.scheduler()
+	workers, workersCtx := errgroup.WithContext(ctx)
+	ch := make(chan IdentifierResults, scheduler.Workers())
+
+	// files which were not identified because of a deadline or cancellation
+	var incomplete []string
+	incompleteMu := sync.Mutex{}
+	addIncomplete := func(files ...string) {
+		incompleteMu.Lock()
+		defer incompleteMu.Unlock()
+		incomplete = append(incomplete, files...)
+	}
+
+	// WaitGroup to know when we have handled all the results
 	waitForResults := sync.WaitGroup{}
 	waitForResults.Add(1)
 
-	// Start receiving the results until channel closes
+	// Start handling the results until channel closes
+	var fnErr error
 	go func() {
 		for ir := range ch {
-			ret = append(ret, ir)
+			if fnErr != nil {
+				continue // keep draining so the workers finish
+			}
+			if fnErr = fn(ir); fnErr != nil {
+				stop()
+			}
 		}
 		waitForResults.Done()
 	}()
 
-	// Loop using a worker to send results to a channel
-	for _, lf := range lfs {
-		lf := lf
+	// Walk the tree using a worker per file to send results to a channel
+	walkErr := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Printf("prevent panic by handling failure accessing a path %q: %v\n", path, err)
+			return err
+		}
+		if err := workersCtx.Err(); err != nil {
+			return err // stop walking a huge tree
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if info, _ := d.Info(); info == nil || info.Size() == 0 {
+			return nil
+		}
+		if err := scheduler.acquire(workersCtx); err != nil {
+			return err
+		}
 		workers.Go(func() error {
-			ir, err := IdentifyLicensesInFile(lf, options, licenseLibrary)
+			defer scheduler.release()
+			ir, err := IdentifyLicensesInFileContext(workersCtx, path, options, licenseLibrary)
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				addIncomplete(path)
+				return nil // a file timeout does not stop the other files
+			}
 			if err == nil {
 				ch <- ir
 			}
 			return err
 		})
-	}
+		return nil
+	})
 
 	// Close the channel when done or error
 	go func() {
@@ -178,12 +470,35 @@ func IdentifyLicensesInDirectory(dirPath string, options Options, licenseLibrary
 		close(ch)
 	}()
 
-	// Make sure we got all the results
+	// Make sure we handled all the results
 	waitForResults.Wait()
-	return ret, err
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return err
+	}
+	if walkErr != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("error walking the path %v: %v\n", dirPath, walkErr)
+			return walkErr
+		}
+		// the walk of the directory tree was not completed
+		addIncomplete(dirPath)
+	}
+	if len(incomplete) == 0 {
+		return nil
+	}
+
+	sort.Strings(incomplete)
+	incompleteErr := ctx.Err()
+	if incompleteErr == nil {
+		incompleteErr = context.DeadlineExceeded // per-file timeouts
+	}
+	return &IncompleteFilesError{Files: incomplete, Err: incompleteErr}
 }
 
-func findAllLicensesInNormalizedData(licenseLibrary *licenses.LicenseLibrary, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
+func findAllLicensesInNormalizedData(ctx context.Context, scheduler *Scheduler, licenseLibrary *licenses.LicenseLibrary, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
 	// initialize the result with original license text, normalized license text, and hash (md5, sha256, and sha512)
 	ret := IdentifierResults{
 		OriginalText:   normalizedData.OriginalText,
@@ -196,8 +511,17 @@ func findAllLicensesInNormalizedData(licenseLibrary *licenses.LicenseLibrary, no
 	// List with LicenseID and indexes for generating text blocks
 	var licensesMatched []licenseMatch
 
+	// only the candidate licenses for the text (found in one pass) can match
+	isCandidate := licenseLibrary.CandidateLicenses(normalizedData.NormalizedText)
+
 	for id, lic := range licenseLibrary.LicenseMap {
-		matches, err := findLicenseInNormalizedData(lic, normalizedData, licenseLibrary)
+		if err := ctx.Err(); err != nil {
+			return ret, err
+		}
+		if !isCandidate(id) {
+			continue
+		}
+		matches, err := findLicenseInNormalizedData(ctx, scheduler, lic, normalizedData, licenseLibrary)
 		if err != nil {
 			return ret, err
 		}
@@ -230,10 +554,41 @@ func findAllLicensesInNormalizedData(licenseLibrary *licenses.LicenseLibrary, no
 	return ret, nil
 }
 
-func findLicenseInNormalizedData(lic licenses.License, normalizedData normalizer.NormalizationData, ll *licenses.LicenseLibrary) (licenseMatches []Match, err error) {
+// findKnownLicensesInNormalizedData returns the known license IDs for the normalized text, each matching the whole text
+func findKnownLicensesInNormalizedData(ids []string, normalizedData normalizer.NormalizationData) (IdentifierResults, error) {
+	ret := IdentifierResults{
+		OriginalText:   normalizedData.OriginalText,
+		NormalizedText: normalizedData.NormalizedText,
+		Hash:           normalizedData.Hash,
+		Matches:        make(map[string][]Match),
+	}
+	if len(normalizedData.IndexMap) == 0 {
+		return ret, nil
+	}
+
+	end := len(normalizedData.NormalizedText) - 1
+	if end < 0 || end >= len(normalizedData.IndexMap) {
+		end = len(normalizedData.IndexMap) - 1
+	}
+	whole := Match{Begins: normalizedData.IndexMap[0], Ends: normalizedData.IndexMap[end]}
+	var licensesMatched []licenseMatch
+	for _, id := range ids {
+		licensesMatched = append(licensesMatched, licenseMatch{LicenseId: id, Match: whole})
+		ret.Matches[id] = []Match{whole}
+	}
+
+	blocks, err := generateTextBlocks(normalizedData.OriginalText, licensesMatched)
+	if err != nil {
+		return ret, err
+	}
+	ret.Blocks = blocks
+	return ret, nil
+}
+
+func findLicenseInNormalizedData(ctx context.Context, scheduler *Scheduler, lic licenses.License, normalizedData normalizer.NormalizationData, ll *licenses.LicenseLibrary) (licenseMatches []Match, err error) {
 	// TODO: If we are not using the match blocks, etc, then do the faster alias checks first.
 	// Get the license pattern matches.
-	licenseMatches, err = findPatterns(lic.PrimaryPatterns, normalizedData, licenseMatches, ll)
+	licenseMatches, err = findPatterns(ctx, scheduler, lic.PrimaryPatterns, normalizedData, licenseMatches, ll)
 	if err != nil {
 		return licenseMatches, err
 	}
@@ -254,7 +609,7 @@ func findLicenseInNormalizedData(lic licenses.License, normalizedData normalizer
 	}
 
 	// If there are associated patterns, check those.
-	return findPatterns(lic.AssociatedPatterns, normalizedData, licenseMatches, ll)
+	return findPatterns(ctx, scheduler, lic.AssociatedPatterns, normalizedData, licenseMatches, ll)
 }
 
 // findAny finds one matching string which meets word boundary conditions (and url conditions)
@@ -406,11 +761,11 @@ func findAnyURL(urls []string, normalized normalizer.NormalizationData, licenseM
 	return findAny(urls, normalized, true, licenseMatches)
 }
 
-func findPatterns(patterns []*licenses.PrimaryPatterns, normalizedData normalizer.NormalizationData, licenseMatches []Match, ll *licenses.LicenseLibrary) ([]Match, error) {
-	// errGroup to do the work in parallel until error
-	workers := errgroup.Group{}
-	workers.SetLimit(10)
-	ch := make(chan []Match, 10)
+func findPatterns(ctx context.Context, scheduler *Scheduler, patterns []*licenses.PrimaryPatterns, normalizedData normalizer.NormalizationData, licenseMatches []Match, ll *licenses.LicenseLibrary) ([]Match, error) {
+	// errGroup to do the work in parallel until error or the context is done
+	// patterns are matched by idle scheduler workers, or else in this goroutine
+	workers, workersCtx := errgroup.WithContext(ctx)
+	ch := make(chan []Match, scheduler.Workers())
 
 	// WaitGroup to know when we have all the results
 	waitForResults := sync.WaitGroup{}
@@ -426,8 +781,12 @@ func findPatterns(patterns []*licenses.PrimaryPatterns, normalizedData normalize
 		waitForResults.Done()
 	}()
 
-	// Loop with the slow part using a worker to send results to a channel
+	// Loop with the slow part using a worker (if one is idle) to send results to a channel
+	var matchErr error
 	for _, pattern := range patterns {
+		if workersCtx.Err() != nil {
+			break // stop starting workers
+		}
 		ppk := licenses.LicensePatternKey{
 			FilePath: pattern.FileName,
 		}
@@ -437,13 +796,25 @@ func findPatterns(patterns []*licenses.PrimaryPatterns, normalizedData normalize
 		}
 		p := pattern
 		nD := normalizedData
-		workers.Go(func() error {
+		match := func() error {
+			if err := workersCtx.Err(); err != nil {
+				return err
+			}
 			patternMatches, err := FindMatchingPatternInNormalizedData(p, nD)
 			if err == nil {
 				ch <- patternMatches
 			}
 			return err
-		})
+		}
+		if scheduler.tryAcquire() {
+			workers.Go(func() error {
+				defer scheduler.release()
+				return match()
+			})
+		} else if err := match(); err != nil {
+			matchErr = err
+			break
+		}
 	}
 
 	// Close the channel when done or error
@@ -455,6 +826,12 @@ func findPatterns(patterns []*licenses.PrimaryPatterns, normalizedData normalize
 
 	// Make sure we got all the results
 	waitForResults.Wait()
+	if err == nil {
+		err = matchErr
+	}
+	if err == nil {
+		err = ctx.Err() // patterns were skipped
+	}
 	return licenseMatches, err
 }
 
@@ -5,9 +5,17 @@
 package identifier
 
 import (
+	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -205,6 +213,223 @@ func Test_identifyLicensesInFile(t *testing.T) {
 	}
 }
 
+func Test_identifyLicensesInFile_encoding(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	// aml is ASCII, so each character is 2 bytes after the byte order mark
+	b := []byte{0xFE, 0xFF}
+	for _, c := range []byte(aml) {
+		b = append(b, 0, c)
+	}
+	filePath := filepath.Join(t.TempDir(), "LICENSE")
+	if err := os.WriteFile(filePath, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := IdentifyLicensesInFile(filePath, defaultOptions(), licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInFile() error = %v", err)
+	}
+	if d := cmp.Diff(normalizer.EncodingUTF16BE, got.Encoding); d != "" {
+		t.Errorf("Didn't get expected encoding: (-want, +got): %v", d)
+	}
+	if d := cmp.Diff(aml, got.OriginalText); d != "" {
+		t.Errorf("Didn't get expected original text: (-want, +got): %v", d)
+	}
+	want := []Match{{Begins: 0, Ends: len(aml) - 1}}
+	if d := cmp.Diff(want, got.Matches["AML"]); d != "" {
+		t.Fatalf("Didn't get expected matches: (-want, +got): %v", d)
+	}
+	if d := cmp.Diff(len(b)-2, got.FileOffset(got.Matches["AML"][0].Ends)); d != "" {
+		t.Errorf("Didn't get expected file offset: (-want, +got): %v", d)
+	}
+}
+
+func Test_identifyLicensesInFile_commentStyle(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	bsd, err := os.ReadFile("../resources/spdx/default/testdata/BSD-3-Clause.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		fileName  string
+		delimiter string
+	}{
+		{fileName: "Library.fs", delimiter: "//"},
+		{fileName: "settings.ini", delimiter: "#"},
+		{fileName: "settings.ini", delimiter: ";"},
+		{fileName: "start.s", delimiter: "#"},
+		{fileName: "index.php", delimiter: "#"},
+		{fileName: "main.tf", delimiter: "//"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.fileName+" "+tc.delimiter, func(t *testing.T) {
+			var commented strings.Builder
+			for _, line := range strings.Split(strings.TrimSpace(string(bsd)), "\n") {
+				commented.WriteString(tc.delimiter + " " + line + "\n")
+			}
+			filePath := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(filePath, []byte(commented.String()), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := IdentifyLicensesInFile(filePath, defaultOptions(), licenseLibrary)
+			if err != nil {
+				t.Fatalf("IdentifyLicensesInFile() error = %v", err)
+			}
+			if _, ok := got.Matches["BSD-3-Clause"]; !ok {
+				t.Errorf("IdentifyLicensesInFile() matches = %v, want BSD-3-Clause", got.Matches)
+			}
+		})
+	}
+}
+
+func Test_identifyLicensesInFile_maxFileSize(t *testing.T) {
+	t.Parallel()
+	// the file is not read, so it may be sparse
+	filePath := filepath.Join(t.TempDir(), "NOTICE")
+	f, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(MaxFileSize + 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = IdentifyLicensesInFile(filePath, Options{}, nil)
+	want := "file too large (10000001 > 10000000)"
+	if err == nil || err.Error() != want {
+		t.Errorf("IdentifyLicensesInFile() error = %v, want %v", err, want)
+	}
+}
+
+func Test_identifyLicensesInFile_largeFiles(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	const filler = "filler\n"
+	// headTail has the license at the start and at the end of the file
+	// (the copyright of the AML template matches the text before the license, so the second match begins with the tail)
+	headTail := aml + "\n" + strings.Repeat(filler, 1200) + aml
+	tail := (len(headTail) - 3000) &^ 1
+	// windows1252 has a Windows-1252 quote in its tail, after an ASCII start
+	windows1252 := aml + "\n" + strings.Repeat(filler, 1200) + "\x93quoted\x94\n" + aml
+	windows1252Tail := (len(windows1252) - 3000) &^ 1
+	// chunks has the license where the first two chunks overlap (at 2500, with chunks at 0 and 3000),
+	// which is matched as in the whole text
+	chunks := strings.Repeat(filler, 357) + "\n" + aml + "\n" + strings.Repeat(filler, 1500)
+	whole, err := IdentifyLicensesInString(chunks, Options{}, licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	if len(whole.Matches["AML"]) != 1 {
+		t.Fatalf("IdentifyLicensesInString() AML matches = %v, want 1 match", whole.Matches["AML"])
+	}
+	tcs := []struct {
+		name         string
+		text         string
+		options      Options
+		wantMatches  []Match
+		wantWindows  []Match
+		wantEncoding string
+		wantErr      string
+	}{
+		{
+			name:    "reject",
+			text:    headTail,
+			options: Options{MaxFileSize: 6000},
+			wantErr: fmt.Sprintf("file too large (%v > 6000)", len(headTail)),
+		},
+		{
+			name:         "head and tail",
+			text:         headTail,
+			options:      Options{MaxFileSize: 6000, LargeFiles: LargeFilesHeadTail},
+			wantMatches:  []Match{{Begins: 0, Ends: len(aml) - 1}, {Begins: tail, Ends: len(headTail) - 1}},
+			wantWindows:  []Match{{Begins: 0, Ends: 2999}, {Begins: tail, Ends: len(headTail) - 1}},
+			wantEncoding: normalizer.EncodingUTF8,
+		},
+		{
+			name:         "single byte encoding after an ASCII head",
+			text:         windows1252,
+			options:      Options{MaxFileSize: 6000, LargeFiles: LargeFilesHeadTail},
+			wantMatches:  []Match{{Begins: 0, Ends: len(aml) - 1}, {Begins: windows1252Tail, Ends: len(windows1252) - 1}},
+			wantWindows:  []Match{{Begins: 0, Ends: 2999}, {Begins: windows1252Tail, Ends: len(windows1252) - 1}},
+			wantEncoding: normalizer.EncodingWindows1252,
+		},
+		{
+			name:         "overlapping chunks",
+			text:         chunks,
+			options:      Options{MaxFileSize: 6000, LargeFiles: LargeFilesChunks, ChunkOverlap: 3000},
+			wantMatches:  whole.Matches["AML"],
+			wantWindows:  []Match{{Begins: 0, Ends: 5999}, {Begins: 3000, Ends: 8999}, {Begins: 6000, Ends: 11999}, {Begins: 9000, Ends: 14999}, {Begins: 12000, Ends: len(chunks) - 1}},
+			wantEncoding: normalizer.EncodingUTF8,
+		},
+		{
+			name:    "overlap too large",
+			text:    chunks,
+			options: Options{MaxFileSize: 6000, LargeFiles: LargeFilesChunks},
+			wantErr: "chunk overlap 200000 must be less than the max file size 6000",
+		},
+		{
+			name:    "unknown policy",
+			text:    chunks,
+			options: Options{LargeFiles: "tail"},
+			wantErr: `unknown large files policy "tail" (expected "reject", "headTail" or "chunks")`,
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			filePath := filepath.Join(t.TempDir(), "THIRD_PARTY_NOTICES.txt")
+			if err := os.WriteFile(filePath, []byte(tc.text), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := IdentifyLicensesInFile(filePath, tc.options, licenseLibrary)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("IdentifyLicensesInFile() error = %v, wantErr %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IdentifyLicensesInFile() error = %v", err)
+			}
+			if d := cmp.Diff(tc.wantMatches, got.Matches["AML"]); d != "" {
+				t.Errorf("Didn't get expected matches: (-want, +got): %v", d)
+			}
+			if d := cmp.Diff(tc.wantWindows, got.Windows); d != "" {
+				t.Errorf("Didn't get expected windows: (-want, +got): %v", d)
+			}
+			if d := cmp.Diff(tc.wantEncoding, got.Encoding); d != "" {
+				t.Errorf("Didn't get expected encoding: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
 //go:embed testfiles/aml.txt
 var aml string
 
@@ -288,9 +513,9 @@ func Test_identifyLicensesInString(t *testing.T) {
 					},
 				},
 				Hash: normalizer.Digest{
-					Md5:    "0b36908f02d0d6ac4364a525ea51e2ea",
-					Sha256: "85e7115e14f4af6f555d7316ef90529bd4cc6bc0bcbd86542412d05d2b8e5b9f",
-					Sha512: "35c95d1d349ca4e2cf2df030b87b7d5b79739af2466fc6bf71d11ddddd11cef5603e0735a7c4d16de505f182174c9a3ab5ffea981149b048685a0cd42317c636",
+					Md5:    "2645d3ffcd2fd12dfbf95908d3323619",
+					Sha256: "fac59436ec4b3cb251acd9ce29fa272e1deb2128315533a491679f565c15dfc1",
+					Sha512: "55f4cda5c96e8547bf90f586eeb41da7ee3c629efbcc71126891e7c2ca7ca63757aa5007af6212ecbe3864c7fe1e225130a1aa642787acd0a3b8227ca3c2c2e4",
 				},
 				CopyRightStatements: []PatternMatch{{Text: "Copyright (C) 2012 by Jun Woong.", Begins: 145, Ends: 176}},
 			},
@@ -794,3 +1019,235 @@ func Test_mutatorsAreCompatible(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentify_KnownHashes(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	const input = "  The Team License applies to this project.\n"
+	normalizedData := normalizer.NormalizationData{OriginalText: input}
+	if err := normalizedData.NormalizeText(); err != nil {
+		t.Fatalf("NormalizeText() error = %v", err)
+	}
+
+	got, err := Identify(Options{}, licenseLibrary, normalizedData)
+	if err != nil {
+		t.Fatalf("Identify() error = %v", err)
+	}
+	if len(got.Matches) != 0 {
+		t.Fatalf("expected no matches for an unknown text, got %v", got.Matches)
+	}
+
+	licenseLibrary.KnownHashes[normalizedData.Hash.Sha256] = []string{"Team-1.0"}
+	got, err = Identify(Options{}, licenseLibrary, normalizedData)
+	if err != nil {
+		t.Fatalf("Identify() error = %v", err)
+	}
+	whole := Match{Begins: 2, Ends: len(input) - 2}
+	if d := cmp.Diff(map[string][]Match{"Team-1.0": {whole}}, got.Matches); d != "" {
+		t.Errorf("Didn't get expected matches: (-want, +got): %v", d)
+	}
+	if d := cmp.Diff("The Team License applies to this project.", got.OriginalText[whole.Begins:whole.Ends+1]); d != "" {
+		t.Errorf("Didn't get expected matched text: (-want, +got): %v", d)
+	}
+}
+
+func TestIdentifyLicensesInString_Pipeline(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	known := normalizer.NormalizationData{OriginalText: "The Team License applies to this project."}
+	if err := known.NormalizeText(); err != nil {
+		t.Fatalf("NormalizeText() error = %v", err)
+	}
+	licenseLibrary.KnownHashes[known.Hash.Sha256] = []string{"Team-1.0"}
+
+	// a step to remove a generated header, so the rest is the known text
+	headerRE := regexp.MustCompile(`code generated by .*? do not edit\.`)
+	pipeline, err := normalizer.DefaultPipeline().InsertAfter(normalizer.StepRemoveCodeCommentIndicators, normalizer.Step{
+		Name: "removeGeneratedHeader",
+		Apply: func(n *normalizer.NormalizationData) error {
+			n.RegexpRemovePatternAndUpdateIndexMap(headerRE)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("InsertAfter() error = %v", err)
+	}
+
+	const header = "// Code generated by acme-gen. DO NOT EDIT.\n"
+	const input = header + "The Team License applies to this project.\n"
+	got, err := IdentifyLicensesInString(input, Options{}, licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	if len(got.Matches) != 0 {
+		t.Fatalf("expected no matches with the default pipeline, got %v", got.Matches)
+	}
+
+	got, err = IdentifyLicensesInString(input, Options{Pipeline: pipeline}, licenseLibrary)
+	if err != nil {
+		t.Fatalf("IdentifyLicensesInString() error = %v", err)
+	}
+	whole := Match{Begins: len(header), Ends: len(input) - 2}
+	if d := cmp.Diff(map[string][]Match{"Team-1.0": {whole}}, got.Matches); d != "" {
+		t.Errorf("Didn't get expected matches: (-want, +got): %v", d)
+	}
+
+	failing := append(normalizer.DefaultPipeline(), normalizer.Step{
+		Name:  "failing",
+		Apply: func(n *normalizer.NormalizationData) error { return errors.New("step failed") },
+	})
+	if _, err := IdentifyLicensesInString(input, Options{Pipeline: failing}, licenseLibrary); err == nil {
+		t.Errorf("IdentifyLicensesInString() expected an error from the failing step")
+	}
+}
+
+func TestIdentifyLicensesInDirectoryContext(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		f := filepath.Join(dir, name)
+		if err := os.WriteFile(f, []byte("This does not contain a license pattern."), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		options        Options
+		wantResults    int
+		wantIncomplete []string
+		wantErr        error
+	}{
+		{
+			name:        "completed",
+			ctx:         context.Background(),
+			wantResults: 2,
+		},
+		{
+			name:           "cancelled",
+			ctx:            cancelled,
+			wantIncomplete: []string{dir},
+			wantErr:        context.Canceled,
+		},
+		{
+			name:           "file timeout",
+			ctx:            context.Background(),
+			options:        Options{FileTimeout: time.Nanosecond},
+			wantIncomplete: files,
+			wantErr:        context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := IdentifyLicensesInDirectoryContext(tt.ctx, dir, tt.options, licenseLibrary)
+			if len(results) != tt.wantResults {
+				t.Errorf("expected %v results, got %v", tt.wantResults, len(results))
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("IdentifyLicensesInDirectoryContext() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("IdentifyLicensesInDirectoryContext() error = %v, want %v", err, tt.wantErr)
+			}
+			var incomplete *IncompleteFilesError
+			if !errors.As(err, &incomplete) {
+				t.Fatalf("expected an IncompleteFilesError, got %v", err)
+			}
+			if d := cmp.Diff(tt.wantIncomplete, incomplete.Files); d != "" {
+				t.Errorf("Didn't get expected incomplete files: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestIdentifyContext_Cancelled(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := IdentifyLicensesInStringContext(ctx, aml, Options{}, licenseLibrary); !errors.Is(err, context.Canceled) {
+		t.Errorf("IdentifyLicensesInStringContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestIdentifyLicensesInDirectoryFunc(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(aml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("each result", func(t *testing.T) {
+		got := make(map[string][]string)
+		err := IdentifyLicensesInDirectoryFunc(context.Background(), dir, Options{}, licenseLibrary, func(ir IdentifierResults) error {
+			for id := range ir.Matches {
+				got[filepath.Base(ir.File)] = append(got[filepath.Base(ir.File)], id)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("IdentifyLicensesInDirectoryFunc() error = %v", err)
+		}
+		want := map[string][]string{"a.txt": {"AML"}, "b.txt": {"AML"}, "c.txt": {"AML"}}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Errorf("Didn't get expected results: (-want, +got): %v", d)
+		}
+	})
+
+	t.Run("stopped by the func", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := IdentifyLicensesInDirectoryFunc(context.Background(), dir, Options{}, licenseLibrary, func(ir IdentifierResults) error {
+			calls++
+			return errStop
+		})
+		if !errors.Is(err, errStop) {
+			t.Errorf("IdentifyLicensesInDirectoryFunc() error = %v, want %v", err, errStop)
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call after the error, got %v", calls)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package identifier
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+var (
+	defaultScheduler     *Scheduler
+	defaultSchedulerOnce sync.Once
+)
+
+// Scheduler limits the number of workers identifying files and matching patterns. One Scheduler is shared by the
+// file-level and the pattern-level work (and by concurrent scans using it), so the work is bounded by its workers
+// however the scans are nested: a file takes a worker, and the patterns of a file take the idle workers or else run
+// in the goroutine of the file.
+type Scheduler struct {
+	workers chan struct{}
+}
+
+// NewScheduler returns a Scheduler with the number of workers, or GOMAXPROCS workers if workers is not positive
+func NewScheduler(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &Scheduler{workers: make(chan struct{}, workers)}
+}
+
+// Workers returns the number of workers
+func (s *Scheduler) Workers() int {
+	return cap(s.workers)
+}
+
+// scheduler returns the scheduler of the options, or the default scheduler (GOMAXPROCS workers) shared by all the
+// scans without one
+func (o Options) scheduler() *Scheduler {
+	if o.Scheduler != nil {
+		return o.Scheduler
+	}
+	defaultSchedulerOnce.Do(func() {
+		defaultScheduler = NewScheduler(0)
+	})
+	return defaultScheduler
+}
+
+// acquire waits for an idle worker until the context is done
+func (s *Scheduler) acquire(ctx context.Context) error {
+	select {
+	case s.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryAcquire takes an idle worker if there is one
+func (s *Scheduler) tryAcquire() bool {
+	select {
+	case s.workers <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Scheduler) release() {
+	<-s.workers
+}
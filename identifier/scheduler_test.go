@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package identifier
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/licenses"
+)
+
+func TestScheduler(t *testing.T) {
+	if got := NewScheduler(0).Workers(); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("expected GOMAXPROCS workers by default, got %v", got)
+	}
+
+	s := NewScheduler(2)
+	if !s.tryAcquire() || !s.tryAcquire() {
+		t.Fatal("expected 2 idle workers")
+	}
+	if s.tryAcquire() {
+		t.Error("expected no idle worker")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire() error = %v, want %v", err, context.Canceled)
+	}
+	s.release()
+	if err := s.acquire(context.Background()); err != nil {
+		t.Errorf("acquire() error = %v", err)
+	}
+}
+
+func TestIdentifyLicensesInDirectory_Workers(t *testing.T) {
+	licenseLibrary, err := licenses.NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary() error = %v", err)
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		// not the exact AML text, so the patterns are matched
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("License: "+aml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, workers := range []int{1, 2, 16} {
+		scheduler := NewScheduler(workers)
+		results, err := IdentifyLicensesInDirectory(dir, Options{Scheduler: scheduler}, licenseLibrary)
+		if err != nil {
+			t.Fatalf("IdentifyLicensesInDirectory() with %v workers error = %v", workers, err)
+		}
+		got := make(map[string]int)
+		for _, r := range results {
+			got[filepath.Base(r.File)] = len(r.Matches["AML"])
+		}
+		if d := cmp.Diff(map[string]int{"a.txt": 1, "b.txt": 1, "c.txt": 1}, got); d != "" {
+			t.Errorf("Didn't get expected AML matches with %v workers: (-want, +got): %v", workers, d)
+		}
+		if !scheduler.tryAcquire() {
+			t.Errorf("expected the %v workers to be released", workers)
+		}
+	}
+}
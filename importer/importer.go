@@ -27,6 +27,12 @@ func AddAllSPDXTemplates(cfg *viper.Viper) error {
 	// input dir is relative to root (if not an absolute path)
 	addAllDir := cfg.GetString("addAll")
 
+	// destination (the embedded resources cannot be updated)
+	rd := cfg.GetString(licenses.Resources)
+	if rd == "" {
+		return fmt.Errorf("a resources directory must be configured to import SPDX licenses")
+	}
+
 	if !path.IsAbs(addAllDir) {
 		addAllDir = path.Join(thisDir, "..", addAllDir)
 	}
@@ -70,8 +76,6 @@ func AddAllSPDXTemplates(cfg *viper.Viper) error {
 	}
 
 	// destinations
-	rd := cfg.GetString(licenses.Resources)
-
 	templateDestDir := getDestPath(rd, licenseListVersion, "template")
 	preCheckDestDir := getDestPath(rd, licenseListVersion, "precheck")
 	textDestDir := getDestPath(rd, licenseListVersion, "testdata")
@@ -111,6 +115,14 @@ func AddAllSPDXTemplates(cfg *viper.Viper) error {
 	if errorCount > 0 {
 		return fmt.Errorf("%v templates could not be validated", errorCount)
 	}
+
+	// Write the known hashes of the imported license texts. They need the custom layers, which may be added later.
+	spdx := cfg.GetString(licenses.SPDX)
+	cfg.Set(licenses.SPDX, licenseListVersion)
+	defer cfg.Set(licenses.SPDX, spdx)
+	if err := WriteKnownHashes(cfg); err != nil {
+		_ = Logger.Errorf("cannot write known hashes (use --knownHashes after adding the custom layers): %v", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/identifier"
+	"github.com/IBM/license-scanner/licenses"
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// WriteKnownHashes identifies each SPDX license text in spdx/<spdx>/testdata with the configured library (including the
+// custom layers) and writes the hash of each normalized text, with the license IDs found in it, to
+// spdx/<spdx>/json/known_hashes.json. A text is only known if its own license ID is found in it.
+func WriteKnownHashes(cfg *viper.Viper) error {
+	rd := cfg.GetString(licenses.Resources)
+	if rd == "" {
+		return fmt.Errorf("a resources directory must be configured to write known hashes")
+	}
+	spdxDir := path.Join(rd, "spdx", cfg.GetString(licenses.SPDX))
+	textDir := path.Join(spdxDir, "testdata")
+	knownHashesFile := path.Join(spdxDir, "json", licenses.KnownHashesJSON)
+
+	licenseLibrary, err := licenses.NewLicenseLibrary(cfg)
+	if err != nil {
+		return err
+	}
+	if err := licenseLibrary.AddAll(); err != nil {
+		return err
+	}
+	// The texts are identified by the patterns, not by the hashes being replaced
+	licenseLibrary.KnownHashes = make(licenses.KnownHashes)
+
+	des, err := os.ReadDir(textDir)
+	if err != nil {
+		return err
+	}
+
+	knownHashes := make(licenses.KnownHashes)
+	mu := sync.Mutex{}
+	workers := errgroup.Group{}
+	workers.SetLimit(runtime.GOMAXPROCS(0))
+	for _, de := range des {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".txt") {
+			continue // skips testdata/invalid
+		}
+		name := de.Name()
+		workers.Go(func() error {
+			id := strings.TrimPrefix(strings.TrimSuffix(name, ".txt"), "deprecated_")
+			b, err := os.ReadFile(path.Join(textDir, name))
+			if err != nil {
+				return err
+			}
+			normalizedData := normalizer.NormalizationData{OriginalText: string(b), Markup: normalizer.DetectMarkup(name, string(b))}
+			if err := licenseLibrary.Normalize(&normalizedData); err != nil {
+				return fmt.Errorf("normalize %v error: %w", name, err)
+			}
+			results, err := identifier.Identify(identifier.Options{}, licenseLibrary, normalizedData)
+			if err != nil {
+				return fmt.Errorf("identify %v error: %w", name, err)
+			}
+			if _, ok := results.Matches[id]; !ok {
+				Logger.Infof("Not a known hash: %v was not found in its own text", id)
+				return nil
+			}
+			ids := make([]string, 0, len(results.Matches))
+			for found := range results.Matches {
+				ids = append(ids, found)
+			}
+			sort.Strings(ids)
+
+			mu.Lock()
+			defer mu.Unlock()
+			knownHashes.Add(normalizedData.Hash.Sha256, ids...)
+			return nil
+		})
+	}
+	if err := workers.Wait(); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(licenses.KnownHashesFile{
+		Custom: cfg.GetStringSlice(configurer.CustomFlag),
+		Hashes: knownHashes,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	Logger.Infof("Writing %v known hashes to %v", len(knownHashes), knownHashesFile)
+	return os.WriteFile(knownHashesFile, append(b, '\n'), 0o600)
+}
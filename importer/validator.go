@@ -62,6 +62,7 @@ func validate(id string, templateBytes []byte, textBytes []byte, templateFile st
 
 	normalizedTestData := normalizer.NormalizationData{
 		OriginalText: string(textBytes),
+		Markup:       normalizer.DetectMarkup("", string(textBytes)),
 	}
 	if err = normalizedTestData.NormalizeText(); err != nil {
 		return
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+// ahoCorasick is an Aho-Corasick automaton which finds all the keywords contained in a text in one pass
+type ahoCorasick struct {
+	root  [256]int32 // transitions from the root state (state 0), which has an edge for every byte
+	nodes []acNode
+}
+
+type acNode struct {
+	edges []acEdge // sorted by byte
+	fail  int32    // the longest proper suffix which is a prefix of a keyword
+	dict  int32    // the nearest fail state with keywords (-1 if none)
+	out   []int32  // the keywords ending in this state
+}
+
+type acEdge struct {
+	b    byte
+	next int32
+}
+
+func (n *acNode) next(b byte) int32 {
+	for _, e := range n.edges {
+		if e.b == b {
+			return e.next
+		}
+		if e.b > b {
+			break
+		}
+	}
+	return -1
+}
+
+// newAhoCorasick builds the automaton for the keywords. Empty keywords are never found.
+func newAhoCorasick(keywords []string) *ahoCorasick {
+	ac := &ahoCorasick{nodes: []acNode{{dict: -1}}}
+
+	// trie
+	for i, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		state := int32(0)
+		for j := 0; j < len(keyword); j++ {
+			b := keyword[j]
+			next := ac.nodes[state].next(b)
+			if next < 0 {
+				next = int32(len(ac.nodes))
+				ac.nodes = append(ac.nodes, acNode{dict: -1})
+				ac.addEdge(state, b, next)
+			}
+			state = next
+		}
+		ac.nodes[state].out = append(ac.nodes[state].out, int32(i))
+	}
+
+	// failure and dictionary links, breadth first
+	queue := make([]int32, 0, len(ac.nodes))
+	for b := 0; b < 256; b++ {
+		next := ac.nodes[0].next(byte(b))
+		if next < 0 {
+			next = 0
+		} else {
+			ac.nodes[next].fail = 0
+			queue = append(queue, next)
+		}
+		ac.root[b] = next
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, e := range ac.nodes[state].edges {
+			fail := ac.nodes[state].fail
+			for fail != 0 && ac.nodes[fail].next(e.b) < 0 {
+				fail = ac.nodes[fail].fail
+			}
+			if fail == 0 {
+				fail = ac.root[e.b]
+			} else {
+				fail = ac.nodes[fail].next(e.b)
+			}
+			ac.nodes[e.next].fail = fail
+			if len(ac.nodes[fail].out) > 0 {
+				ac.nodes[e.next].dict = fail
+			} else {
+				ac.nodes[e.next].dict = ac.nodes[fail].dict
+			}
+			queue = append(queue, e.next)
+		}
+	}
+	return ac
+}
+
+func (ac *ahoCorasick) addEdge(state int32, b byte, next int32) {
+	edges := ac.nodes[state].edges
+	i := 0
+	for i < len(edges) && edges[i].b < b {
+		i++
+	}
+	edges = append(edges, acEdge{})
+	copy(edges[i+1:], edges[i:])
+	edges[i] = acEdge{b: b, next: next}
+	ac.nodes[state].edges = edges
+}
+
+// find calls found with the index of each keyword contained in the text.
+// Each keyword may be reported more than once.
+func (ac *ahoCorasick) find(text string, found func(keyword int32)) {
+	state := int32(0)
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		for {
+			if state == 0 {
+				state = ac.root[b]
+				break
+			}
+			if next := ac.nodes[state].next(b); next >= 0 {
+				state = next
+				break
+			}
+			state = ac.nodes[state].fail
+		}
+		for s := state; s > 0; s = ac.nodes[s].dict {
+			for _, keyword := range ac.nodes[s].out {
+				found(keyword)
+			}
+		}
+	}
+}
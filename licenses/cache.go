@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// libraryCacheVersion must be incremented whenever the cache format or the normalized pattern output changes
+// (see normalizer.NormalizationData.NormalizeText). Changes to the data files of the normalizer change the
+// fingerprint without an increment (see normalizer.DataFingerprint).
+const libraryCacheVersion = 6
+
+// libraryCache is the serialized form of a LicenseLibrary with pre-normalized patterns
+type libraryCache struct {
+	Version                   int
+	Fingerprint               string
+	SPDXVersion               string
+	Licenses                  map[string]cachedLicense
+	PrimaryPatternPreCheckMap PrimaryPatternPreCheckMap
+	AcceptablePatterns        map[string]string
+	KnownHashes               KnownHashes
+}
+
+type cachedLicense struct {
+	SPDXLicenseID      string
+	LicenseInfo        LicenseInfo
+	PrimaryPatterns    []cachedPattern
+	AssociatedPatterns []cachedPattern
+	Aliases            []string
+	URLs               []string
+	Text               LicenseText
+}
+
+type cachedPattern struct {
+	Text          string
+	FileName      string
+	Regex         string // empty if the pattern could not be normalized (the error is reproduced when used)
+	CaptureGroups []*normalizer.CaptureGroup
+}
+
+// addAllWithCache loads the library from the cache file if it is current, otherwise it loads the
+// resources and replaces the cache file.
+func (ll *LicenseLibrary) addAllWithCache(cacheFile string) error {
+	fingerprint, err := ll.Fingerprint()
+	if err != nil {
+		return err
+	}
+
+	err = ll.readCache(cacheFile, fingerprint)
+	if err == nil {
+		Logger.Debugf("Loaded %v licenses from library cache %v", len(ll.LicenseMap), cacheFile)
+		return nil
+	}
+	Logger.Debugf("Not using library cache %v: %v", cacheFile, err)
+
+	if err := ll.addAll(); err != nil {
+		return err
+	}
+
+	if err := ll.writeCache(cacheFile, fingerprint); err != nil {
+		// The library is loaded, so a cache that cannot be written is not fatal
+		_ = Logger.Errorf("cannot write library cache %v: %v", cacheFile, err)
+	}
+	return nil
+}
+
+// Fingerprint returns a hash of the paths and contents of the resource files used by the library,
+// with the cache version and the data files of the normalizer.
+// Any change to the resources changes the fingerprint, even when a file keeps its size and modification time
+// (e.g. copied with cp -p or extracted from an archive).
+func (ll *LicenseLibrary) Fingerprint() (string, error) {
+	roots := []string{path.Join("spdx", ll.Config.GetString(SPDX))}
+	for _, layer := range customLayers(ll.Config) {
+		roots = append(roots, path.Join(customDir, layer))
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "version=%v\n", libraryCacheVersion)
+	_, _ = fmt.Fprintf(h, "normalizer=%v\n", normalizer.DataFingerprint())
+	_, _ = fmt.Fprintf(h, "resources=%v\n", ll.resourcesPath)
+	for _, root := range roots {
+		_, _ = fmt.Fprintf(h, "root=%v\n", root)
+		if err := fs.WalkDir(ll.resourcesFS, root, func(p string, de fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil // missing resources are okay (same as loading)
+				}
+				return err
+			}
+			if de.IsDir() {
+				if de.Name() == "testdata" {
+					return fs.SkipDir // not used by the library
+				}
+				return nil
+			}
+			b, err := fs.ReadFile(ll.resourcesFS, p)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(h, "%v %v\n", p, len(b))
+			_, _ = h.Write(b)
+			return nil
+		}); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (ll *LicenseLibrary) readCache(cacheFile string, fingerprint string) error {
+	f, err := os.Open(cacheFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var cache libraryCache
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&cache); err != nil {
+		return fmt.Errorf("decode error: %w", err)
+	}
+	if cache.Version != libraryCacheVersion {
+		return fmt.Errorf("cache version %v is not %v", cache.Version, libraryCacheVersion)
+	}
+	if cache.Fingerprint != fingerprint {
+		return fmt.Errorf("resources have changed")
+	}
+
+	acceptablePatternsMap := make(PatternsMap)
+	for id, source := range cache.AcceptablePatterns {
+		re, err := regexp.Compile(source)
+		if err != nil {
+			return err
+		}
+		acceptablePatternsMap[id] = re
+	}
+
+	licenseMap := make(LicenseMap)
+	for id, cl := range cache.Licenses {
+		l := License{
+			SPDXLicenseID: cl.SPDXLicenseID,
+			LicenseInfo:   cl.LicenseInfo,
+			Aliases:       cl.Aliases,
+			URLs:          cl.URLs,
+			Text:          cl.Text,
+		}
+		for _, cp := range cl.PrimaryPatterns {
+			l.PrimaryPatternsSources = append(l.PrimaryPatternsSources, PrimaryPatternsSources{SourceText: cp.Text, Filename: cp.FileName})
+			l.PrimaryPatterns = append(l.PrimaryPatterns, cp.toPrimaryPatterns())
+		}
+		for _, cp := range cl.AssociatedPatterns {
+			l.AssociatedPatternsSources = append(l.AssociatedPatternsSources, PrimaryPatternsSources{SourceText: cp.Text, Filename: cp.FileName})
+			l.AssociatedPatterns = append(l.AssociatedPatterns, cp.toPrimaryPatterns())
+		}
+		licenseMap[id] = l
+	}
+
+	ll.SPDXVersion = cache.SPDXVersion
+	ll.LicenseMap = licenseMap
+	ll.PrimaryPatternPreCheckMap = cache.PrimaryPatternPreCheckMap
+	if ll.PrimaryPatternPreCheckMap == nil {
+		ll.PrimaryPatternPreCheckMap = make(PrimaryPatternPreCheckMap)
+	}
+	ll.AcceptablePatternsMap = acceptablePatternsMap
+	ll.KnownHashes = cache.KnownHashes
+	if ll.KnownHashes == nil {
+		ll.KnownHashes = make(KnownHashes)
+	}
+	return nil
+}
+
+// writeCache normalizes every pattern and writes the library to a temp file which is renamed to the cache file
+func (ll *LicenseLibrary) writeCache(cacheFile string, fingerprint string) error {
+	if err := ll.generateAllPatterns(); err != nil {
+		return err
+	}
+
+	cache := libraryCache{
+		Version:                   libraryCacheVersion,
+		Fingerprint:               fingerprint,
+		SPDXVersion:               ll.SPDXVersion,
+		Licenses:                  make(map[string]cachedLicense, len(ll.LicenseMap)),
+		PrimaryPatternPreCheckMap: ll.PrimaryPatternPreCheckMap,
+		AcceptablePatterns:        make(map[string]string, len(ll.AcceptablePatternsMap)),
+		KnownHashes:               ll.KnownHashes,
+	}
+	for id, re := range ll.AcceptablePatternsMap {
+		cache.AcceptablePatterns[id] = re.String()
+	}
+	for id, l := range ll.LicenseMap {
+		cl := cachedLicense{
+			SPDXLicenseID: l.SPDXLicenseID,
+			LicenseInfo:   l.LicenseInfo,
+			Aliases:       l.Aliases,
+			URLs:          l.URLs,
+			Text:          l.Text,
+		}
+		for _, pp := range l.PrimaryPatterns {
+			cl.PrimaryPatterns = append(cl.PrimaryPatterns, newCachedPattern(pp))
+		}
+		for _, pp := range l.AssociatedPatterns {
+			cl.AssociatedPatterns = append(cl.AssociatedPatterns, newCachedPattern(pp))
+		}
+		cache.Licenses[id] = cl
+	}
+
+	dir := filepath.Dir(cacheFile)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(cacheFile)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	w := bufio.NewWriter(tmp)
+	if err := gob.NewEncoder(w).Encode(&cache); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// Rename so that concurrent readers never see a partially written cache
+	return os.Rename(tmp.Name(), cacheFile)
+}
+
+// generateAllPatterns normalizes and compiles all the primary and associated patterns (in parallel).
+// Patterns with errors are skipped here. The error is returned when the pattern is used.
+func (ll *LicenseLibrary) generateAllPatterns() error {
+	ll.setPatternPipelines()
+	workers := errgroup.Group{}
+	workers.SetLimit(runtime.GOMAXPROCS(0))
+	for _, l := range ll.LicenseMap {
+		for _, patterns := range [][]*PrimaryPatterns{l.PrimaryPatterns, l.AssociatedPatterns} {
+			for _, pp := range patterns {
+				pp := pp
+				workers.Go(func() error {
+					_, _ = GenerateMatchingPatternFromSourceText(pp)
+					return nil
+				})
+			}
+		}
+	}
+	return workers.Wait()
+}
+
+func newCachedPattern(pp *PrimaryPatterns) cachedPattern {
+	cp := cachedPattern{
+		Text:          pp.Text,
+		FileName:      pp.FileName,
+		CaptureGroups: pp.CaptureGroups,
+	}
+	if pp.re != nil {
+		cp.Regex = pp.re.String()
+	}
+	return cp
+}
+
+func (cp cachedPattern) toPrimaryPatterns() *PrimaryPatterns {
+	return &PrimaryPatterns{
+		Text:          cp.Text,
+		FileName:      cp.FileName,
+		CaptureGroups: cp.CaptureGroups,
+		regex:         cp.Regex,
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/license-scanner/configurer"
+)
+
+func TestLicenseLibrary_LibraryCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache", "library.gob")
+
+	flagSet := configurer.NewDefaultFlags()
+	if err := flagSet.Set(configurer.LibraryCacheFlag, cacheFile); err != nil {
+		t.Fatal(err)
+	}
+	config, err := configurer.InitConfig(flagSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First load reads the resources and writes the cache
+	built, err := NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := built.AddAll(); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Fatalf("expected cache file to be written: %v", err)
+	}
+
+	// Second load reads the cache
+	fingerprint, err := built.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cached.readCache(cacheFile, fingerprint); err != nil {
+		t.Fatalf("readCache() error = %v", err)
+	}
+
+	if cached.SPDXVersion != built.SPDXVersion {
+		t.Errorf("SPDXVersion = %v, want %v", cached.SPDXVersion, built.SPDXVersion)
+	}
+	if len(cached.LicenseMap) != len(built.LicenseMap) {
+		t.Errorf("len(LicenseMap) = %v, want %v", len(cached.LicenseMap), len(built.LicenseMap))
+	}
+	if len(cached.PrimaryPatternPreCheckMap) != len(built.PrimaryPatternPreCheckMap) {
+		t.Errorf("len(PrimaryPatternPreCheckMap) = %v, want %v", len(cached.PrimaryPatternPreCheckMap), len(built.PrimaryPatternPreCheckMap))
+	}
+	if len(cached.AcceptablePatternsMap) != len(built.AcceptablePatternsMap) {
+		t.Errorf("len(AcceptablePatternsMap) = %v, want %v", len(cached.AcceptablePatternsMap), len(built.AcceptablePatternsMap))
+	}
+
+	for _, id := range []string{"MIT", "Apache-2.0"} {
+		want := built.LicenseMap[id]
+		got := cached.LicenseMap[id]
+		if len(got.PrimaryPatterns) != len(want.PrimaryPatterns) || len(got.PrimaryPatterns) == 0 {
+			t.Fatalf("%v: got %v primary patterns, want %v", id, len(got.PrimaryPatterns), len(want.PrimaryPatterns))
+		}
+		for i := range want.PrimaryPatterns {
+			wantRE, err := GenerateMatchingPatternFromSourceText(want.PrimaryPatterns[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotRE, err := GenerateMatchingPatternFromSourceText(got.PrimaryPatterns[i])
+			if err != nil {
+				t.Fatalf("%v: cached pattern error = %v", id, err)
+			}
+			if gotRE.String() != wantRE.String() {
+				t.Errorf("%v: cached pattern %v differs from the generated pattern", id, got.PrimaryPatterns[i].FileName)
+			}
+		}
+	}
+
+	// A changed fingerprint (resources changed) rejects the cache
+	stale, err := NewLicenseLibrary(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.readCache(cacheFile, "stale"); err == nil {
+		t.Error("expected readCache() to reject a cache with a different fingerprint")
+	}
+}
+
+func TestLicenseLibrary_Fingerprint(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "spdx", "default", "template", "MIT.template.txt")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	fingerprint := func(text string) string {
+		if err := os.WriteFile(file, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		ll, err := NewLicenseLibraryFS(os.DirFS(dir), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fingerprint, err := ll.Fingerprint()
+		if err != nil {
+			t.Fatalf("Fingerprint() error = %v", err)
+		}
+		return fingerprint
+	}
+
+	original := fingerprint("MIT License")
+	if got := fingerprint("MIT License"); got != original {
+		t.Errorf("Fingerprint() = %v for the same resources, want %v", got, original)
+	}
+	// A template replaced with one of the same size and modification time changes the fingerprint
+	if got := fingerprint("MIT Licence"); got == original {
+		t.Error("expected a different fingerprint for a changed template")
+	}
+}
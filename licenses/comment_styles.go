@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sync"
+
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// CommentStylesJSON is the optional file in a custom layer which adds or replaces comment styles by language,
+// e.g. {"vb": {"extensions": [".vb"], "line": ["'", "rem"]}}. A custom style takes over its extensions and
+// interpreters from the built-in styles.
+const CommentStylesJSON = "comment_styles.json"
+
+var (
+	defaultCommentStylesOnce sync.Once
+	defaultCommentStyles     normalizer.CommentStyles
+)
+
+// loadCommentStyles merges the comment styles of the custom layers, in order, with the built-in styles.
+// The styles are validated, so an invalid file fails the library load.
+func (ll *LicenseLibrary) loadCommentStyles() error {
+	styles, err := normalizer.DefaultCommentStyles()
+	if err != nil {
+		return fmt.Errorf("invalid built-in comment styles: %w", err)
+	}
+	for _, layer := range customLayers(ll.Config) {
+		f := path.Join(customDir, layer, CommentStylesJSON)
+		b, err := fs.ReadFile(ll.resourcesFS, f)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // optional
+			}
+			return err
+		}
+		layerStyles, err := normalizer.ParseCommentStyles(b)
+		if err != nil {
+			return fmt.Errorf("comment styles %v error: %w", ll.resourceName(f), err)
+		}
+		styles = styles.Merge(layerStyles)
+	}
+	ll.commentStyles = styles
+	return nil
+}
+
+// CommentStyle returns the comment style of a text by the file name or the shebang of the text,
+// or nil when the style is not known and the generic comment indicators are removed
+func (ll *LicenseLibrary) CommentStyle(fileName string, text string) *normalizer.CommentStyle {
+	styles := ll.commentStyles
+	if styles == nil { // the library was not loaded with AddAll
+		defaultCommentStylesOnce.Do(func() {
+			var err error
+			if defaultCommentStyles, err = normalizer.DefaultCommentStyles(); err != nil {
+				_ = Logger.Errorf("invalid built-in comment styles: %v", err)
+			}
+		})
+		styles = defaultCommentStyles
+	}
+	return styles.Select(fileName, text)
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/resources"
+)
+
+func TestLicenseLibrary_CommentStyle(t *testing.T) {
+	layers := fstest.MapFS{
+		"custom/objc/comment_styles.json":    {Data: []byte(`{"objc": {"extensions": [".m"], "line": ["//"], "block": [{"begin": "/*", "end": "*/"}]}}`)},
+		"custom/invalid/comment_styles.json": {Data: []byte(`{"x": {"extensions": [".x"]}}`)},
+	}
+	fsys := resources.Overlay(testResourcesFS, layers)
+
+	tests := []struct {
+		name     string
+		custom   string
+		fileName string
+		want     string
+		wantErr  string
+	}{
+		{name: "built-in style", custom: "default", fileName: "main.m", want: "c"},
+		{name: "layer style takes over the extension", custom: "default,objc", fileName: "main.m", want: "objc"},
+		{name: "unknown", custom: "default,objc", fileName: "NOTICE", want: ""},
+		{name: "invalid style", custom: "default,invalid", wantErr: "comment styles custom/invalid/comment_styles.json error: comment style \"x\" has no comment delimiters"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			flagSet := configurer.NewDefaultFlags()
+			if err := flagSet.Set(configurer.CustomFlag, tt.custom); err != nil {
+				t.Fatal(err)
+			}
+			config, err := configurer.InitConfig(flagSet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ll, err := NewLicenseLibraryFS(fsys, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ll.AddAll()
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("AddAll() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+			got := ""
+			if style := ll.CommentStyle(tt.fileName, ""); style != nil {
+				got = style.Name()
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Didn't get expected comment style: (-want, +got): %v", d)
+			}
+		})
+	}
+}
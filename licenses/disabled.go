@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// DisabledJSON is the optional file in a custom layer which disables licenses and patterns loaded by earlier layers
+const DisabledJSON = "disabled.json"
+
+// Disabled lists the licenses and patterns that a custom layer removes from the library
+type Disabled struct {
+	// Licenses are the IDs of licenses to remove
+	Licenses []string `json:"licenses"`
+	// Patterns are primary or associated patterns to remove as "<ID>/<file name>", e.g. "MIT/license_MIT.txt" or "MIT/MIT.template.txt"
+	Patterns []string `json:"patterns"`
+	// AcceptablePatterns are the IDs of acceptable patterns to remove
+	AcceptablePatterns []string `json:"acceptable_patterns"`
+}
+
+// applyDisabled removes what the layer's disabled.json disables from the layers loaded so far (including this one)
+func (ll *LicenseLibrary) applyDisabled(layer string) error {
+	f := path.Join(customDir, layer, DisabledJSON)
+	b, err := fs.ReadFile(ll.resourcesFS, f)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // optional
+		}
+		return err
+	}
+	var disabled Disabled
+	if err := json.Unmarshal(b, &disabled); err != nil {
+		return fmt.Errorf("unmarshal %v error: %w", ll.resourceName(f), err)
+	}
+
+	// known hashes are removed for the licenses that are disabled or have disabled patterns
+	changed := make(map[string]bool)
+
+	for _, id := range disabled.Licenses {
+		l, ok := ll.LicenseMap[id]
+		if !ok {
+			Logger.Debugf("Disabled license %v from %v is not loaded", id, ll.resourceName(f))
+			continue
+		}
+		for _, patterns := range [][]*PrimaryPatterns{l.PrimaryPatterns, l.AssociatedPatterns} {
+			for _, pp := range patterns {
+				delete(ll.PrimaryPatternPreCheckMap, LicensePatternKey{FilePath: pp.FileName})
+			}
+		}
+		delete(ll.LicenseMap, id)
+		delete(ll.customLicenseInfo, id)
+		changed[id] = true
+	}
+
+	for _, p := range disabled.Patterns {
+		id, fileName, found := strings.Cut(p, "/")
+		if !found || id == "" || fileName == "" {
+			return fmt.Errorf("disabled pattern %q in %v is not <ID>/<file name>", p, ll.resourceName(f))
+		}
+		l, ok := ll.LicenseMap[id]
+		if !ok {
+			Logger.Debugf("Disabled pattern %v from %v is not loaded", p, ll.resourceName(f))
+			continue
+		}
+		var removed []string
+		l.PrimaryPatterns, removed = withoutPattern(l.PrimaryPatterns, fileName, removed)
+		l.AssociatedPatterns, removed = withoutPattern(l.AssociatedPatterns, fileName, removed)
+		l.PrimaryPatternsSources = withoutPatternSource(l.PrimaryPatternsSources, fileName)
+		l.AssociatedPatternsSources = withoutPatternSource(l.AssociatedPatternsSources, fileName)
+		if len(removed) == 0 {
+			Logger.Debugf("Disabled pattern %v from %v is not loaded", p, ll.resourceName(f))
+		}
+		for _, filePath := range removed {
+			delete(ll.PrimaryPatternPreCheckMap, LicensePatternKey{FilePath: filePath})
+		}
+		ll.LicenseMap[id] = l
+		changed[id] = true
+	}
+	ll.removeKnownHashes(changed)
+
+	for _, id := range disabled.AcceptablePatterns {
+		delete(ll.AcceptablePatternsMap, id)
+	}
+	return nil
+}
+
+// withoutPattern returns the patterns without those with the file name, and appends the removed file paths to removed
+func withoutPattern(patterns []*PrimaryPatterns, fileName string, removed []string) ([]*PrimaryPatterns, []string) {
+	var ret []*PrimaryPatterns
+	for _, pp := range patterns {
+		if path.Base(pp.FileName) == fileName {
+			removed = append(removed, pp.FileName)
+			continue
+		}
+		ret = append(ret, pp)
+	}
+	return ret, removed
+}
+
+func withoutPatternSource(sources []PrimaryPatternsSources, fileName string) []PrimaryPatternsSources {
+	var ret []PrimaryPatternsSources
+	for _, ps := range sources {
+		if path.Base(ps.Filename) != fileName {
+			ret = append(ret, ps)
+		}
+	}
+	return ret
+}
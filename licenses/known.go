@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+const (
+	// KnownHashesJSON is the file in spdx/<spdx>/json with the SHA-256 digests of the normalized SPDX license texts
+	// (testdata) and the license IDs identified in each text. It is written by the importer.
+	KnownHashesJSON = "known_hashes.json"
+	// ExamplePattern is the prefix of example license texts in a custom license directory (e.g. example_MIT.txt).
+	// A text with the same normalized text as an example is identified as the license without matching the patterns.
+	ExamplePattern = "example_"
+)
+
+// KnownHashes maps the SHA-256 digest of a normalized license text to the license IDs found in the text
+type KnownHashes map[string][]string
+
+// KnownHashesFile is the content of KnownHashesJSON. The license IDs found depend on the custom layers, so the
+// hashes are only used with the same custom layers.
+type KnownHashesFile struct {
+	Custom []string    `json:"custom"`
+	Hashes KnownHashes `json:"hashes"`
+}
+
+// addKnownHashes adds the known hashes of the SPDX license texts, if they were found with the configured custom layers.
+// A missing file is okay.
+func (ll *LicenseLibrary) addKnownHashes() error {
+	knownHashesJSON := path.Join("spdx", ll.Config.GetString(SPDX), jsonDir, KnownHashesJSON)
+	b, err := fs.ReadFile(ll.resourcesFS, knownHashesJSON)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var knownHashesFile KnownHashesFile
+	if err := json.Unmarshal(b, &knownHashesFile); err != nil {
+		return fmt.Errorf("unmarshal %v error: %w", ll.resourceName(knownHashesJSON), err)
+	}
+	if layers := customLayers(ll.Config); strings.Join(layers, ",") != strings.Join(knownHashesFile.Custom, ",") {
+		Logger.Debugf("Not using %v for custom layers %v (written for %v)", ll.resourceName(knownHashesJSON), layers, knownHashesFile.Custom)
+		return nil
+	}
+	for sha256, ids := range knownHashesFile.Hashes {
+		ll.KnownHashes[sha256] = ids
+	}
+	return nil
+}
+
+// addExample adds the hash of an example license text for the license ID
+func (ll *LicenseLibrary) addExample(id string, fileContents []byte, filePath string) error {
+	normalizedData := normalizer.NormalizationData{
+		OriginalText: string(fileContents),
+		Markup:       normalizer.DetectMarkup(filePath, string(fileContents)),
+	}
+	if err := ll.Normalize(&normalizedData); err != nil {
+		return fmt.Errorf("normalize example %v error: %w", filePath, err)
+	}
+	// An example from a layer replaces what was known about the same text
+	ll.KnownHashes[normalizedData.Hash.Sha256] = []string{id}
+	return nil
+}
+
+// removeKnownHashes removes the known hashes which include any of the license IDs,
+// because the licenses (or their patterns) were removed
+func (ll *LicenseLibrary) removeKnownHashes(ids map[string]bool) {
+	for sha256, knownIDs := range ll.KnownHashes {
+		for _, id := range knownIDs {
+			if ids[id] {
+				delete(ll.KnownHashes, sha256)
+				break
+			}
+		}
+	}
+}
+
+// Add adds the license IDs found in a normalized text, keeping the IDs sorted and unique
+func (k KnownHashes) Add(sha256 string, ids ...string) {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, id := range append(k[sha256], ids...) {
+		if !seen[id] {
+			seen[id] = true
+			merged = append(merged, id)
+		}
+	}
+	sort.Strings(merged)
+	k[sha256] = merged
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/normalizer"
+	"github.com/IBM/license-scanner/resources"
+)
+
+func TestLicenseLibrary_KnownHashes(t *testing.T) {
+	sha256 := func(t *testing.T, text string) string {
+		t.Helper()
+		nd := normalizer.NormalizationData{OriginalText: text}
+		if err := nd.NormalizeText(); err != nil {
+			t.Fatal(err)
+		}
+		return nd.Hash.Sha256
+	}
+	mitHash := sha256(t, "Permission is hereby granted, free of charge")
+	teamHash := sha256(t, "The Team License\n\nTeam license text")
+
+	layers := fstest.MapFS{
+		"spdx/default/json/known_hashes.json":                       {Data: []byte(`{"custom": ["default"], "hashes": {"` + mitHash + `": ["MIT"]}}`)},
+		"custom/default/license_patterns/Team-1.0/example_Team.txt": {Data: []byte("The  Team   License\nTeam license text\n")},
+		"custom/team/disabled.json":                                 {Data: []byte(`{"patterns": ["Team-1.0/license_Team.txt"]}`)},
+	}
+	fsys := resources.Overlay(testResourcesFS, layers)
+
+	newLibrary := func(t *testing.T, custom string) *LicenseLibrary {
+		t.Helper()
+		flagSet := configurer.NewDefaultFlags()
+		if err := flagSet.Set(configurer.CustomFlag, custom); err != nil {
+			t.Fatal(err)
+		}
+		config, err := configurer.InitConfig(flagSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ll, err := NewLicenseLibraryFS(fsys, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ll.AddAll(); err != nil {
+			t.Fatalf("AddAll() error = %v", err)
+		}
+		return ll
+	}
+
+	tests := []struct {
+		name   string
+		custom string
+		want   KnownHashes
+	}{
+		{
+			name:   "SPDX hashes and examples",
+			custom: "default",
+			want:   KnownHashes{mitHash: {"MIT"}, teamHash: {"Team-1.0"}},
+		},
+		{
+			name:   "SPDX hashes are not used with other layers",
+			custom: "default,team",
+			want:   KnownHashes{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ll := newLibrary(t, tt.custom)
+			if d := cmp.Diff(tt.want, ll.KnownHashes); d != "" {
+				t.Errorf("Didn't get expected known hashes: (-want, +got): %v", d)
+			}
+		})
+	}
+
+	t.Run("default resources", func(t *testing.T) {
+		ll, err := NewLicenseLibrary(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ll.AddAll(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ll.KnownHashes) == 0 {
+			t.Error("expected the known hashes of the default SPDX license texts")
+		}
+	})
+}
@@ -7,16 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"io/ioutil"
-	"os"
 	"path"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/resources"
 
 	"github.com/spf13/viper"
 
@@ -66,7 +64,21 @@ type LicenseLibrary struct {
 	LicenseMap                LicenseMap
 	PrimaryPatternPreCheckMap PrimaryPatternPreCheckMap
 	AcceptablePatternsMap     PatternsMap
-	Config                    *viper.Viper
+	// KnownHashes identifies license texts by the hash of the normalized text, without matching the patterns
+	KnownHashes KnownHashes
+	Config      *viper.Viper
+	// resourcesFS holds the configured resources directory, or the embedded default resources
+	resourcesFS fs.FS
+	// resourcesPath is the configured resources directory used to name the loaded files ("" when embedded)
+	resourcesPath string
+	// customLicenseInfo holds the license_info.json fields merged from the custom layers loaded so far
+	customLicenseInfo map[string]LicenseInfo
+	// prefilter selects the candidate licenses for a text (built by AddAll)
+	prefilter *prefilter
+	// pipeline normalizes with the replacement words of the custom layers (nil for the default pipeline)
+	pipeline normalizer.Pipeline
+	// commentStyles are the built-in comment styles merged with the comment styles of the custom layers
+	commentStyles normalizer.CommentStyles
 }
 
 type LicensePreChecks struct {
@@ -104,16 +116,42 @@ func NewLicenseLibrary(config *viper.Viper) (*LicenseLibrary, error) {
 		config = cfg
 	}
 
+	resourcesPath := config.GetString(Resources)
 	ll := LicenseLibrary{
 		LicenseMap:                make(LicenseMap),
 		PrimaryPatternPreCheckMap: make(PrimaryPatternPreCheckMap),
 		AcceptablePatternsMap:     make(PatternsMap),
+		KnownHashes:               make(KnownHashes),
 		Config:                    config,
+		resourcesFS:               resources.FS(resourcesPath),
+		resourcesPath:             resourcesPath,
+		customLicenseInfo:         make(map[string]LicenseInfo),
 	}
 
 	return &ll, nil
 }
 
+// NewLicenseLibraryFS creates a license library which loads the resources from fsys instead of the configured resources directory.
+// fsys has the layout of a resources directory (spdx/<spdx>/... and custom/<custom>/...), and may be, for example, an embed.FS,
+// a zip.Reader, an fstest.MapFS or an overlay of several trees (see resources.Overlay).
+func NewLicenseLibraryFS(fsys fs.FS, config *viper.Viper) (*LicenseLibrary, error) {
+	ll, err := NewLicenseLibrary(config)
+	if err != nil {
+		return nil, err
+	}
+	ll.resourcesFS = fsys
+	ll.resourcesPath = ""
+	return ll, nil
+}
+
+// resourceName returns the name used for a file in the resources (the file path, unless the resources are embedded)
+func (ll *LicenseLibrary) resourceName(name string) string {
+	if ll.resourcesPath == "" {
+		return name
+	}
+	return path.Join(ll.resourcesPath, name)
+}
+
 type LicenseMap map[string]License
 
 // License holds the specification of each license
@@ -139,6 +177,8 @@ type PrimaryPatterns struct {
 	Text          string
 	doOnce        sync.Once
 	re            *regexp.Regexp
+	regex         string              // pre-normalized regex source (from the library cache) to compile instead of normalizing Text
+	pipeline      normalizer.Pipeline // normalizes Text (nil for the default pipeline)
 	CaptureGroups []*normalizer.CaptureGroup
 	FileName      string
 }
@@ -228,9 +268,9 @@ func toSliceOfStrings(got interface{}) []string {
 	}
 }
 
-// readLicenseInfoJSON unmarshalls the json bytes into LicenseInfo
-func readLicenseInfoJSON(fileContents []byte) (*LicenseInfo, error) {
-	var licenseInfo LicenseInfo
+// readLicenseInfoJSON unmarshalls the json bytes into LicenseInfo. Fields which are not in the json keep the base values.
+func readLicenseInfoJSON(fileContents []byte, base LicenseInfo) (*LicenseInfo, error) {
+	licenseInfo := base
 	if err := json.Unmarshal(fileContents, &licenseInfo); err != nil {
 		return nil, err
 	}
@@ -254,57 +294,82 @@ func (l License) GetID() string {
 	}
 }
 
+// AddAll adds the SPDX and custom licenses from the resources.
+// If a library cache file is configured, the library is loaded from the cache when the cache is current.
 func (ll *LicenseLibrary) AddAll() error {
+	if err := ll.loadReplacementWords(); err != nil {
+		return err
+	}
+	if err := ll.loadCommentStyles(); err != nil {
+		return err
+	}
+	var err error
+	if cacheFile := ll.Config.GetString(configurer.LibraryCacheFlag); cacheFile != "" {
+		err = ll.addAllWithCache(cacheFile)
+	} else {
+		err = ll.addAll()
+	}
+	if err != nil {
+		return err
+	}
+	ll.setPatternPipelines()
+	ll.buildPrefilter()
+	return nil
+}
+
+func (ll *LicenseLibrary) addAll() error {
 	if err := ll.AddAllSPDX(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		// not exist is okay for now. Assuming legacy resources
 		return err
 	}
+	if err := ll.addKnownHashes(); err != nil {
+		return err
+	}
 	return ll.AddAllLegacy()
 }
 
 func (ll *LicenseLibrary) AddAllSPDX() error {
-	resourcesPath := ll.Config.GetString(Resources)
 	SPDXDir := ll.Config.GetString(SPDX)
 	// templateMap := make(map[string]string)
-	templatePath := path.Join(resourcesPath, "spdx", SPDXDir, template)
-	jsonPath := path.Join(resourcesPath, "spdx", SPDXDir, jsonDir)
+	templatePath := path.Join("spdx", SPDXDir, template)
+	jsonPath := path.Join("spdx", SPDXDir, jsonDir)
 
 	licensesJSON := path.Join(jsonPath, "licenses.json")
-	SPDXLicenseListBytes, err := os.ReadFile(licensesJSON)
+	SPDXLicenseListBytes, err := fs.ReadFile(ll.resourcesFS, licensesJSON)
 	if err != nil {
-		return fmt.Errorf("read SPDXLicenseListJSON from %v error: %w", licensesJSON, err)
+		return fmt.Errorf("read SPDXLicenseListJSON from %v error: %w", ll.resourceName(licensesJSON), err)
 	}
 	licenseList, err := ReadSPDXLicenseListJSON(SPDXLicenseListBytes)
 	if err != nil {
-		return fmt.Errorf("unmarshal SPDXLicenseListJSON from %v error: %w", licensesJSON, err)
+		return fmt.Errorf("unmarshal SPDXLicenseListJSON from %v error: %w", ll.resourceName(licensesJSON), err)
 	}
 
 	ll.SPDXVersion = licenseList.LicenseListVersion
 
 	exceptionsJSON := path.Join(jsonPath, "exceptions.json")
-	SPDXExceptionsListBytes, err := os.ReadFile(exceptionsJSON)
+	SPDXExceptionsListBytes, err := fs.ReadFile(ll.resourcesFS, exceptionsJSON)
 	if err != nil {
-		return fmt.Errorf("read exceptions JSON from %v error: %w", exceptionsJSON, err)
+		return fmt.Errorf("read exceptions JSON from %v error: %w", ll.resourceName(exceptionsJSON), err)
 	}
 	exceptionsList, err := ReadSPDXLicenseListJSON(SPDXExceptionsListBytes)
 	if err != nil {
-		return fmt.Errorf("unmarshal SPDXLicenseListJSON from %v error: %w", exceptionsJSON, err)
+		return fmt.Errorf("unmarshal SPDXLicenseListJSON from %v error: %w", ll.resourceName(exceptionsJSON), err)
 	}
 
 	for _, sl := range licenseList.Licenses {
 		id := sl.LicenseID
 		f := getTemplateFilePath(id, sl.IsDeprecatedLicenseID, templatePath)
-		tBytes, err := os.ReadFile(f)
+		tBytes, err := fs.ReadFile(ll.resourcesFS, f)
 		if err != nil {
-			if os.IsNotExist(err) {
-				Logger.Debugf("Skipping missing template file '%v'", f)
+			if errors.Is(err, fs.ErrNotExist) {
+				Logger.Debugf("Skipping missing template file '%v'", ll.resourceName(f))
 				continue
 			}
 			return err
 		}
 
 		l := ll.LicenseMap[id]
-		if err := AddPrimaryPatternAndSource(string(tBytes), f, &l); err != nil {
+		if err := AddPrimaryPatternAndSource(string(tBytes), ll.resourceName(f), &l); err != nil {
 			return err
 		}
 		l.SPDXLicenseID = id
@@ -320,17 +385,17 @@ func (ll *LicenseLibrary) AddAllSPDX() error {
 	for _, se := range exceptionsList.Exceptions {
 		id := se.LicenseExceptionID
 		f := getTemplateFilePath(id, se.IsDeprecatedLicenseID, templatePath)
-		tBytes, err := os.ReadFile(f)
+		tBytes, err := fs.ReadFile(ll.resourcesFS, f)
 		if err != nil {
-			if os.IsNotExist(err) {
-				Logger.Debugf("Skipping missing template file '%v'", f)
+			if errors.Is(err, fs.ErrNotExist) {
+				Logger.Debugf("Skipping missing template file '%v'", ll.resourceName(f))
 				continue
 			}
 			return err
 		}
 
 		l := ll.LicenseMap[id]
-		if err := AddPrimaryPatternAndSource(string(tBytes), f, &l); err != nil {
+		if err := AddPrimaryPatternAndSource(string(tBytes), ll.resourceName(f), &l); err != nil {
 			return err
 		}
 		l.SPDXLicenseID = id
@@ -342,8 +407,8 @@ func (ll *LicenseLibrary) AddAllSPDX() error {
 	}
 
 	preCheckMap := make(map[string]string)
-	preCheckPath := path.Join(resourcesPath, "spdx", SPDXDir, precheck)
-	if err := filepath.WalkDir(preCheckPath, func(path string, de fs.DirEntry, err error) error {
+	preCheckPath := path.Join("spdx", SPDXDir, precheck)
+	if err := fs.WalkDir(ll.resourcesFS, preCheckPath, func(path string, de fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -352,7 +417,7 @@ func (ll *LicenseLibrary) AddAllSPDX() error {
 			if de.Name() == precheck {
 				return nil // walk the template dir
 			}
-			return filepath.SkipDir // ignore any other dirs
+			return fs.SkipDir // ignore any other dirs
 		}
 
 		if strings.HasSuffix(de.Name(), ".json") {
@@ -365,13 +430,13 @@ func (ll *LicenseLibrary) AddAllSPDX() error {
 
 	for id, f := range preCheckMap {
 
-		fileContents, err := os.ReadFile(f)
+		fileContents, err := fs.ReadFile(ll.resourcesFS, f)
 		if err != nil {
 			return err
 		}
 
 		isDeprecated := ll.LicenseMap[id].LicenseInfo.IsDeprecated
-		templateFilePath := getTemplateFilePath(id, isDeprecated, templatePath)
+		templateFilePath := ll.resourceName(getTemplateFilePath(id, isDeprecated, templatePath))
 		if err := addPreChecks(fileContents, templateFilePath, ll); err != nil {
 			return err
 		}
@@ -389,20 +454,36 @@ func getTemplateFilePath(id string, isDeprecated bool, templatePath string) stri
 	return f
 }
 
+// AddAllLegacy adds the acceptable patterns and license patterns from each of the custom layers in order.
+// Later layers can add patterns, override license_info.json fields and acceptable patterns,
+// and disable patterns or licenses from earlier layers (see DisabledJSON).
 func (ll *LicenseLibrary) AddAllLegacy() error {
-	if err := ll.addAcceptablePatternsFromBundledLibrary(); err != nil {
-		return err
+	for _, layer := range customLayers(ll.Config) {
+		if err := ll.addCustomLayer(layer); err != nil {
+			return err
+		}
 	}
 	Logger.Debugf("Loaded %v acceptable patterns", len(ll.AcceptablePatternsMap))
-
-	if err := ll.AddLicenses(); err != nil {
-		return err
-	}
 	Logger.Debugf("Loaded %v licenses", len(ll.LicenseMap))
 
 	return nil
 }
 
+func (ll *LicenseLibrary) addCustomLayer(layer string) error {
+	layerDir := path.Join(customDir, layer)
+	if _, err := fs.Stat(ll.resourcesFS, layerDir); err != nil {
+		return fmt.Errorf("custom layer %v error: %w", ll.resourceName(layerDir), err)
+	}
+
+	if err := ll.addAcceptablePatternsFromBundledLibrary(layer); err != nil {
+		return err
+	}
+	if err := ll.addLicenses(layer); err != nil {
+		return err
+	}
+	return ll.applyDisabled(layer)
+}
+
 func (ll *LicenseLibrary) addAcceptablePattern(patternId string, source string) error {
 	if _, ok := ll.AcceptablePatternsMap[patternId]; ok {
 		return fmt.Errorf("An acceptable pattern already exists with the ID %v", patternId)
@@ -416,9 +497,19 @@ func (ll *LicenseLibrary) addAcceptablePattern(patternId string, source string)
 	return nil
 }
 
-func (ll *LicenseLibrary) addAcceptablePatternsFromBundledLibrary() error {
-	_, acceptablePatternsPath := getResourcePaths(ll.Config)
-	if err := ll.addRegexFromSourceToLibrary(acceptablePatternsPath, ll.addAcceptablePattern); err != nil && !os.IsNotExist(err) {
+// addAcceptablePatternsFromBundledLibrary adds the acceptable patterns from a custom layer.
+// An acceptable pattern replaces any pattern with the same ID from an earlier layer.
+func (ll *LicenseLibrary) addAcceptablePatternsFromBundledLibrary(layer string) error {
+	_, acceptablePatternsPath := getResourcePaths(layer)
+	layerPatterns := make(map[string]bool)
+	override := func(patternId string, source string) error {
+		if !layerPatterns[patternId] {
+			delete(ll.AcceptablePatternsMap, patternId) // from an earlier layer
+		}
+		layerPatterns[patternId] = true
+		return ll.addAcceptablePattern(patternId, source)
+	}
+	if err := ll.addRegexFromSourceToLibrary(acceptablePatternsPath, override); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		// Ignoring IsNotExist to make acceptable patterns optional, but other errs are not ok
 		return err
 	}
@@ -426,9 +517,9 @@ func (ll *LicenseLibrary) addAcceptablePatternsFromBundledLibrary() error {
 }
 
 func (ll *LicenseLibrary) addRegexFromSourceToLibrary(sourceDir string, addFunction addFunc) error {
-	files, err := ioutil.ReadDir(sourceDir)
+	files, err := fs.ReadDir(ll.resourcesFS, sourceDir)
 	if err != nil {
-		if !os.IsNotExist(err) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			return err
 		} else {
 			return nil
@@ -440,40 +531,56 @@ func (ll *LicenseLibrary) addRegexFromSourceToLibrary(sourceDir string, addFunct
 			continue
 		}
 		fileName := file.Name()
-		patternId := fileName[:len(fileName)-len(filepath.Ext(fileName))]
-		source, err := ioutil.ReadFile(path.Join(sourceDir, fileName))
+		patternId := fileName[:len(fileName)-len(path.Ext(fileName))]
+		source, err := fs.ReadFile(ll.resourcesFS, path.Join(sourceDir, fileName))
 		if err != nil {
 			return err
 		}
 		if err := addFunction(patternId, string(source)); err != nil {
-			_ = Logger.Errorf("invalid regex from %v/%v with error: %v", sourceDir, fileName, err)
+			_ = Logger.Errorf("invalid regex from %v/%v with error: %v", ll.resourceName(sourceDir), fileName, err)
 			return err
 		}
 	}
 	return nil
 }
 
-func getResourcePaths(cfg *viper.Viper) (licensePatternsPath, acceptablePatternsPath string) {
-	rd := cfg.GetString(Resources)
-	customVersionedDir := cfg.GetString(configurer.CustomFlag)
-	licensePatternsPath = path.Join(rd, customDir, customVersionedDir, LicensePatterns)
-	acceptablePatternsPath = path.Join(rd, customDir, customVersionedDir, AcceptablePatterns)
+// customLayers returns the custom layers in the order they are applied
+func customLayers(cfg *viper.Viper) []string {
+	return cfg.GetStringSlice(configurer.CustomFlag)
+}
+
+// getResourcePaths returns the pattern paths of a custom layer within the resources
+func getResourcePaths(layer string) (licensePatternsPath, acceptablePatternsPath string) {
+	licensePatternsPath = path.Join(customDir, layer, LicensePatterns)
+	acceptablePatternsPath = path.Join(customDir, layer, AcceptablePatterns)
 	return
 }
 
 // AddLicenses initializes the license data set to scan the input license file against
 // all the possible licenses available in the resources are read
 func (ll *LicenseLibrary) AddLicenses() error {
-	licensePatternsPath, _ := getResourcePaths(ll.Config)
-	licenseIds, err := ioutil.ReadDir(licensePatternsPath)
+	for _, layer := range customLayers(ll.Config) {
+		if err := ll.addLicenses(layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ll *LicenseLibrary) addLicenses(layer string) error {
+	licensePatternsPath, _ := getResourcePaths(layer)
+	licenseIds, err := fs.ReadDir(ll.resourcesFS, licensePatternsPath)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // a layer may only have acceptable patterns or disabled.json
+		}
 		return err
 	}
 
 	// retrieve each license ID based on the directory name, i.e. resources/license_patterns/licenseID
 	// for example, resources/license_patterns/MIT
 	for _, id := range licenseIds {
-		err := AddLicense(id.Name(), ll)
+		err := ll.addLicense(id.Name(), layer)
 		if err != nil {
 			_ = Logger.Errorf("AddLicense error on %v: %v", id.Name(), err)
 			return err
@@ -482,13 +589,32 @@ func (ll *LicenseLibrary) AddLicenses() error {
 	return nil
 }
 
+// AddLicense adds the license patterns for the license ID from each custom layer which has them
 func AddLicense(id string, ll *LicenseLibrary) error {
+	found := false
+	for _, layer := range customLayers(ll.Config) {
+		err := ll.addLicense(id, layer)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("license %v not found in custom layers %v: %w", id, customLayers(ll.Config), fs.ErrNotExist)
+	}
+	return nil
+}
+
+func (ll *LicenseLibrary) addLicense(id string, layer string) error {
 	l, existed := ll.LicenseMap[id]
 
-	licensePatternsPath, _ := getResourcePaths(ll.Config)
+	licensePatternsPath, _ := getResourcePaths(layer)
 	// license directory is at the LicensePatternsPath/id
 	licenseDirectory := path.Join(licensePatternsPath, id)
-	directoryContents, err := ioutil.ReadDir(licenseDirectory)
+	directoryContents, err := fs.ReadDir(ll.resourcesFS, licenseDirectory)
 	if err != nil {
 		return err
 	}
@@ -501,21 +627,23 @@ func AddLicense(id string, ll *LicenseLibrary) error {
 			continue
 		}
 		// read the file contents, determine the file path by joining licenseDirectory (LicensePatternsPath/id) and file name
-		fileContents, err := ioutil.ReadFile(path.Join(licenseDirectory, file.Name()))
+		fileContents, err := fs.ReadFile(ll.resourcesFS, path.Join(licenseDirectory, file.Name()))
 		if err != nil {
 			return err
 		}
 		fileName := file.Name()
-		filePath := path.Join(licenseDirectory, fileName)
+		filePath := ll.resourceName(path.Join(licenseDirectory, fileName))
 		lowerFileName := strings.ToLower(fileName)
 
 		switch {
 		// the JSON payload is always stored in license_info.txt
 		case lowerFileName == LicenseInfoJSON:
-			payload, err := readLicenseInfoJSON(fileContents)
+			// A later layer only overrides the fields in its license_info.json
+			payload, err := readLicenseInfoJSON(fileContents, ll.customLicenseInfo[id])
 			if err != nil {
 				return Logger.Errorf("Unmarshal LicenseInfo from %v using LicenseReader error: %v", file.Name(), err)
 			}
+			ll.customLicenseInfo[id] = *payload
 
 			if l.SPDXLicenseID == "" {
 				if payload.SPDXStandard {
@@ -557,7 +685,7 @@ func AddLicense(id string, ll *LicenseLibrary) error {
 			}
 			l.URLs = urls
 
-			if existed { // merge the additional LicenseInfo with the existing SPDX attributes
+			if existed && l.LicenseInfo.SPDXStandard { // merge the additional LicenseInfo with the existing SPDX attributes
 				if l.LicenseInfo.Name != "" {
 					payload.Name = l.LicenseInfo.Name // Use first name we got (from SPDX), if not empty
 				}
@@ -570,6 +698,12 @@ func AddLicense(id string, ll *LicenseLibrary) error {
 			}
 			l.LicenseInfo = *payload
 
+		// files starting with "example_" are example texts of the license
+		case strings.HasPrefix(lowerFileName, ExamplePattern):
+			if err := ll.addExample(id, fileContents, filePath); err != nil {
+				return err
+			}
+
 		// all other files starting with "license_" are primary license patterns
 		case strings.HasPrefix(lowerFileName, PrimaryPattern):
 			if err := AddPrimaryPatternAndSource(string(fileContents), filePath, &l); err != nil {
@@ -581,7 +715,7 @@ func AddLicense(id string, ll *LicenseLibrary) error {
 			sourceFile := strings.TrimPrefix(fileName, PreChecksPattern)
 			ext := path.Ext(sourceFile)
 			sourceFile = sourceFile[0:len(sourceFile)-len(ext)] + ".txt" // Replace .json with .txt
-			filePath := path.Join(licenseDirectory, sourceFile)
+			filePath := ll.resourceName(path.Join(licenseDirectory, sourceFile))
 			if err := addPreChecks(fileContents, filePath, ll); err != nil {
 				return err
 			}
@@ -638,9 +772,19 @@ func AddPrimaryPatternAndSource(fileContents string, filePath string, l *License
 func GenerateMatchingPatternFromSourceText(pp *PrimaryPatterns) (*regexp.Regexp, error) {
 	var err error
 	pp.doOnce.Do(func() {
+		if pp.regex != "" {
+			// Already normalized and generated (from the library cache)
+			pp.re, err = regexp.Compile(pp.regex)
+			return
+		}
+
 		// Normalize the input text.
 		normalizedData := normalizer.NewNormalizationData(pp.Text, true)
-		err = normalizedData.NormalizeText()
+		if pp.pipeline != nil {
+			err = pp.pipeline.Normalize(normalizedData)
+		} else {
+			err = normalizedData.NormalizeText()
+		}
 		if err == nil {
 			var re *regexp.Regexp
 			re, err = GenerateRegexFromNormalizedText(normalizedData.NormalizedText)
@@ -713,8 +857,13 @@ func List(config *viper.Viper) (lics []Detail, deprecatedLics []Detail, exceptio
 		return
 	}
 
+	lics, deprecatedLics, exceptions, deprecatedExceptions = ll.List()
 	spdxVersion = ll.SPDXVersion
+	return
+}
 
+// List returns the details of the licenses and exceptions in the library, sorted by ID
+func (ll *LicenseLibrary) List() (lics []Detail, deprecatedLics []Detail, exceptions []Exception, deprecatedExceptions []Exception) {
 	lm := ll.LicenseMap
 
 	// Sort by key
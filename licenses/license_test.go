@@ -5,14 +5,19 @@
 package licenses
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
+	"io/fs"
 	"regexp"
 	"sort"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/resources"
 )
 
 const (
@@ -256,6 +261,16 @@ func TestLicenseLibrary_AddAll(t *testing.T) {
 		t.Fatalf("NewLicenseLibrary(configWithResources) error = %v", err)
 	}
 
+	embeddedConfig, err := configurer.InitConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embeddedConfig.Set(Resources, "") // no resources dir uses the embedded resources
+	embeddedLL, err := NewLicenseLibrary(embeddedConfig)
+	if err != nil {
+		t.Fatalf("NewLicenseLibrary(embeddedConfig) error = %v", err)
+	}
+
 	tests := []struct {
 		name          string
 		ll            *LicenseLibrary
@@ -279,6 +294,15 @@ func TestLicenseLibrary_AddAll(t *testing.T) {
 				"AcceptablePatternsMap":     acceptablePatternsCount,
 			},
 		},
+		{
+			name: "embedded resources",
+			ll:   embeddedLL,
+			expectedSizes: map[string]int{
+				"LicenseMap":                expectedLicenseCount,
+				"PrimaryPatternPreCheckMap": expectedPrecheckCount,
+				"AcceptablePatternsMap":     acceptablePatternsCount,
+			},
+		},
 		{
 			name: "config resources path",
 			ll:   configWithResourcesLL,
@@ -311,6 +335,205 @@ func TestLicenseLibrary_AddAll(t *testing.T) {
 	}
 }
 
+// testResourcesFS is a minimal in-memory resources tree with one SPDX license and one custom license
+var testResourcesFS = fstest.MapFS{
+	"spdx/default/json/licenses.json":                            {Data: []byte(`{"licenseListVersion": "0.1", "licenses": [{"licenseId": "MIT", "name": "MIT License", "isOsiApproved": true}]}`)},
+	"spdx/default/json/exceptions.json":                          {Data: []byte(`{"licenseListVersion": "0.1", "exceptions": []}`)},
+	"spdx/default/template/MIT.template.txt":                     {Data: []byte("Permission is hereby granted, free of charge")},
+	"spdx/default/precheck/MIT.json":                             {Data: []byte(`{"StaticBlocks": ["permission is hereby granted"]}`)},
+	"custom/default/license_patterns/Team-1.0/license_info.json": {Data: []byte(`{"name": "Team License 1.0", "family": "Team"}`)},
+	"custom/default/license_patterns/Team-1.0/license_Team.txt":  {Data: []byte("Team license text")},
+	"custom/default/acceptable_patterns/team_header.txt":         {Data: []byte("team header")},
+}
+
+func TestNewLicenseLibraryFS(t *testing.T) {
+	// The same tree in a zip
+	var zipBytes bytes.Buffer
+	zw := zip.NewWriter(&zipBytes)
+	for name, f := range testResourcesFS {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(f.Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zipFS, err := zip.NewReader(bytes.NewReader(zipBytes.Bytes()), int64(zipBytes.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An overlay which adds a custom license to the tree
+	teamFS := fstest.MapFS{
+		"custom/default/license_patterns/Team-2.0/license_info.json": {Data: []byte(`{"name": "Team License 2.0", "family": "Team"}`)},
+		"custom/default/license_patterns/Team-2.0/license_Team.txt":  {Data: []byte("Team license 2 text")},
+	}
+
+	tests := []struct {
+		name          string
+		fsys          fs.FS
+		wantLicenses  []string
+		wantPrechecks []string
+	}{
+		{
+			name:          "MapFS",
+			fsys:          testResourcesFS,
+			wantLicenses:  []string{"MIT", "Team-1.0"},
+			wantPrechecks: []string{"spdx/default/template/MIT.template.txt"},
+		},
+		{
+			name:          "zip",
+			fsys:          zipFS,
+			wantLicenses:  []string{"MIT", "Team-1.0"},
+			wantPrechecks: []string{"spdx/default/template/MIT.template.txt"},
+		},
+		{
+			name:          "overlay",
+			fsys:          resources.Overlay(testResourcesFS, teamFS),
+			wantLicenses:  []string{"MIT", "Team-1.0", "Team-2.0"},
+			wantPrechecks: []string{"spdx/default/template/MIT.template.txt"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ll, err := NewLicenseLibraryFS(tt.fsys, nil)
+			if err != nil {
+				t.Fatalf("NewLicenseLibraryFS() error = %v", err)
+			}
+			if err := ll.AddAll(); err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+
+			var gotLicenses []string
+			for id := range ll.LicenseMap {
+				gotLicenses = append(gotLicenses, id)
+			}
+			sort.Strings(gotLicenses)
+			if d := cmp.Diff(tt.wantLicenses, gotLicenses); d != "" {
+				t.Errorf("Didn't get expected licenses: (-want, +got): %v", d)
+			}
+
+			var gotPrechecks []string
+			for key := range ll.PrimaryPatternPreCheckMap {
+				gotPrechecks = append(gotPrechecks, key.FilePath)
+			}
+			if d := cmp.Diff(tt.wantPrechecks, gotPrechecks); d != "" {
+				t.Errorf("Didn't get expected prechecks: (-want, +got): %v", d)
+			}
+
+			if ll.SPDXVersion != "0.1" {
+				t.Errorf("SPDXVersion = %v, want 0.1", ll.SPDXVersion)
+			}
+			if _, ok := ll.AcceptablePatternsMap["team_header"]; !ok {
+				t.Error("expected acceptable pattern team_header")
+			}
+			if got := ll.LicenseMap["Team-1.0"].PrimaryPatterns[0].FileName; got != "custom/default/license_patterns/Team-1.0/license_Team.txt" {
+				t.Errorf("FileName = %v", got)
+			}
+		})
+	}
+}
+
+func TestLicenseLibrary_CustomLayers(t *testing.T) {
+	layers := fstest.MapFS{
+		"custom/org/license_patterns/Team-1.0/license_info.json": {Data: []byte(`{"family": "Org", "aliases": ["Team License"]}`)},
+		"custom/org/license_patterns/MIT/license_MIT-org.txt":    {Data: []byte("Org MIT text")},
+		"custom/org/acceptable_patterns/team_header.txt":         {Data: []byte("org header")},
+		"custom/team/disabled.json": {Data: []byte(`{
+  "licenses": ["Team-1.0"],
+  "patterns": ["MIT/MIT.template.txt"],
+  "acceptable_patterns": ["team_header"]
+}`)},
+	}
+	fsys := resources.Overlay(testResourcesFS, layers)
+
+	newLibrary := func(t *testing.T, custom string) *LicenseLibrary {
+		t.Helper()
+		flagSet := configurer.NewDefaultFlags()
+		if err := flagSet.Set(configurer.CustomFlag, custom); err != nil {
+			t.Fatal(err)
+		}
+		config, err := configurer.InitConfig(flagSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ll, err := NewLicenseLibraryFS(fsys, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ll.AddAll(); err != nil {
+			t.Fatalf("AddAll() error = %v", err)
+		}
+		return ll
+	}
+
+	t.Run("org overrides and adds", func(t *testing.T) {
+		ll := newLibrary(t, "default,org")
+
+		info := ll.LicenseMap["Team-1.0"].LicenseInfo
+		if info.Name != "Team License 1.0" || info.Family != "Org" {
+			t.Errorf("expected name from default and family from org, got %+v", info)
+		}
+		if d := cmp.Diff([]string{"team license", "team-1.0", "team license 1.0"}, ll.LicenseMap["Team-1.0"].Aliases); d != "" {
+			t.Errorf("Didn't get expected aliases: (-want, +got): %v", d)
+		}
+
+		var fileNames []string
+		for _, pp := range ll.LicenseMap["MIT"].PrimaryPatterns {
+			fileNames = append(fileNames, pp.FileName)
+		}
+		want := []string{"spdx/default/template/MIT.template.txt", "custom/org/license_patterns/MIT/license_MIT-org.txt"}
+		if d := cmp.Diff(want, fileNames); d != "" {
+			t.Errorf("Didn't get expected MIT patterns: (-want, +got): %v", d)
+		}
+
+		if re := ll.AcceptablePatternsMap["team_header"]; re == nil || !re.MatchString("ORG HEADER") {
+			t.Errorf("expected the org acceptable pattern to override the default, got %v", re)
+		}
+	})
+
+	t.Run("team disables", func(t *testing.T) {
+		ll := newLibrary(t, "default,org,team")
+
+		if _, ok := ll.LicenseMap["Team-1.0"]; ok {
+			t.Error("expected Team-1.0 to be disabled")
+		}
+		mit := ll.LicenseMap["MIT"]
+		if len(mit.PrimaryPatterns) != 1 || len(mit.PrimaryPatternsSources) != 1 || mit.PrimaryPatterns[0].FileName != "custom/org/license_patterns/MIT/license_MIT-org.txt" {
+			t.Errorf("expected only the org MIT pattern, got %v patterns", len(mit.PrimaryPatterns))
+		}
+		if len(ll.PrimaryPatternPreCheckMap) != 0 {
+			t.Errorf("expected the MIT template prechecks to be disabled, got %v", ll.PrimaryPatternPreCheckMap)
+		}
+		if _, ok := ll.AcceptablePatternsMap["team_header"]; ok {
+			t.Error("expected team_header to be disabled")
+		}
+	})
+
+	t.Run("missing layer", func(t *testing.T) {
+		flagSet := configurer.NewDefaultFlags()
+		if err := flagSet.Set(configurer.CustomFlag, "default,nope"); err != nil {
+			t.Fatal(err)
+		}
+		config, err := configurer.InitConfig(flagSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ll, err := NewLicenseLibraryFS(fsys, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ll.AddAll(); err == nil {
+			t.Error("expected an error for a missing custom layer")
+		}
+	})
+}
+
 func TestLicenseLibrary_PreChecks(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -385,7 +608,7 @@ func TestLicenseUnmarshal(t *testing.T) {
 }
 `)
 
-	li, err := readLicenseInfoJSON(fileContents)
+	li, err := readLicenseInfoJSON(fileContents, LicenseInfo{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+// prefilter selects the candidate licenses for a normalized text in one pass over the text. A license can only match
+// if the text contains one of its keywords: the first static block of a primary pattern (a pattern can only match if
+// the text contains all its static blocks), an alias or a URL. A license with a primary pattern without static blocks
+// is always a candidate.
+type prefilter struct {
+	automaton *ahoCorasick
+	// keywordLicenses are the license IDs by keyword
+	keywordLicenses [][]string
+	// always are the licenses which are always candidates
+	always []string
+	// licenses are the licenses in the library when the prefilter was built. Licenses added later are always candidates.
+	licenses map[string]bool
+}
+
+// buildPrefilter builds the prefilter for the licenses in the library. It is built when the library is loaded.
+func (ll *LicenseLibrary) buildPrefilter() {
+	pf := &prefilter{licenses: make(map[string]bool, len(ll.LicenseMap))}
+	keywordIndex := make(map[string]int)
+	var keywords []string
+	addKeyword := func(keyword string, id string) {
+		i, ok := keywordIndex[keyword]
+		if !ok {
+			i = len(keywords)
+			keywordIndex[keyword] = i
+			keywords = append(keywords, keyword)
+			pf.keywordLicenses = append(pf.keywordLicenses, nil)
+		}
+		pf.keywordLicenses[i] = append(pf.keywordLicenses[i], id)
+	}
+
+	for id, lic := range ll.LicenseMap {
+		pf.licenses[id] = true
+		always := false
+		for _, pattern := range lic.PrimaryPatterns {
+			preChecks := ll.PrimaryPatternPreCheckMap[LicensePatternKey{FilePath: pattern.FileName}]
+			if preChecks == nil || len(preChecks.StaticBlocks) == 0 || preChecks.StaticBlocks[0] == "" {
+				always = true
+				break
+			}
+			addKeyword(preChecks.StaticBlocks[0], id)
+		}
+		for _, s := range append(append([]string{}, lic.Aliases...), lic.URLs...) {
+			if s == "" {
+				always = true // an empty string is found in any text
+				break
+			}
+			addKeyword(s, id)
+		}
+		if always {
+			pf.always = append(pf.always, id)
+		}
+	}
+
+	pf.automaton = newAhoCorasick(keywords)
+	ll.prefilter = pf
+}
+
+// CandidateLicenses returns a func which reports whether a license may match the normalized text, so its patterns,
+// aliases and URLs need to be matched. Licenses which cannot match are skipped. If the library was not loaded with
+// AddAll, every license is a candidate.
+func (ll *LicenseLibrary) CandidateLicenses(normalizedText string) func(id string) bool {
+	pf := ll.prefilter
+	if pf == nil {
+		return func(string) bool { return true }
+	}
+
+	candidates := make(map[string]bool)
+	for _, id := range pf.always {
+		candidates[id] = true
+	}
+	found := make([]bool, len(pf.keywordLicenses))
+	pf.automaton.find(normalizedText, func(keyword int32) {
+		if found[keyword] {
+			return
+		}
+		found[keyword] = true
+		for _, id := range pf.keywordLicenses[keyword] {
+			candidates[id] = true
+		}
+	})
+
+	return func(id string) bool {
+		return candidates[id] || !pf.licenses[id]
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+func Test_ahoCorasick(t *testing.T) {
+	keywords := []string{"he", "she", "his", "hers", "", "s"}
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "overlapping keywords", text: "ushers", want: []string{"he", "hers", "s", "she"}},
+		{name: "keyword after a failure", text: "ahishe", want: []string{"he", "his", "s", "she"}},
+		{name: "no keywords", text: "xyz", want: nil},
+		{name: "empty text", text: "", want: nil},
+	}
+	ac := newAhoCorasick(keywords)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			found := make(map[string]bool)
+			ac.find(tt.text, func(keyword int32) {
+				found[keywords[keyword]] = true
+			})
+			var got []string
+			for keyword := range found {
+				got = append(got, keyword)
+			}
+			sort.Strings(got)
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("Didn't get expected keywords: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestLicenseLibrary_CandidateLicenses(t *testing.T) {
+	ll, err := NewLicenseLibrary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ll.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	normalize := func(t *testing.T, text string) string {
+		t.Helper()
+		nd := normalizer.NormalizationData{OriginalText: text}
+		if err := nd.NormalizeText(); err != nil {
+			t.Fatal(err)
+		}
+		return nd.NormalizedText
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		wantCandidate []string
+		wantSkipped   []string
+	}{
+		{
+			name:          "MIT text",
+			text:          "Permission is hereby granted, free of charge, to any person obtaining a copy of this software",
+			wantCandidate: []string{"MIT"},
+			wantSkipped:   []string{"Apache-2.0", "GPL-2.0"},
+		},
+		{
+			name:          "URL",
+			text:          "See http://www.apache.org/licenses/LICENSE-2.0 for details",
+			wantCandidate: []string{"Apache-2.0"},
+			wantSkipped:   []string{"MIT"},
+		},
+		{
+			name:        "no license",
+			text:        "func main() {}",
+			wantSkipped: []string{"MIT", "Apache-2.0", "GPL-2.0"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			isCandidate := ll.CandidateLicenses(normalize(t, tt.text))
+			for _, id := range tt.wantCandidate {
+				if !isCandidate(id) {
+					t.Errorf("expected %v to be a candidate", id)
+				}
+			}
+			for _, id := range tt.wantSkipped {
+				if isCandidate(id) {
+					t.Errorf("expected %v to be skipped", id)
+				}
+			}
+		})
+	}
+
+	t.Run("license added after the prefilter", func(t *testing.T) {
+		if !ll.CandidateLicenses("")("Added-1.0") {
+			t.Error("expected a license added after AddAll to be a candidate")
+		}
+	})
+
+	t.Run("without the prefilter", func(t *testing.T) {
+		spdxOnly, err := NewLicenseLibrary(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !spdxOnly.CandidateLicenses("")("MIT") {
+			t.Error("expected every license to be a candidate without AddAll")
+		}
+	})
+}
+
+func BenchmarkLicenseLibrary_CandidateLicenses(b *testing.B) {
+	ll, err := NewLicenseLibrary(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := ll.AddAll(); err != nil {
+		b.Fatal(err)
+	}
+	nd := normalizer.NormalizationData{OriginalText: "Licensed under the Apache License, Version 2.0 (the \"License\"); you may not use this file except in compliance with the License."}
+	if err := nd.NormalizeText(); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ll.CandidateLicenses(nd.NormalizedText)
+	}
+}
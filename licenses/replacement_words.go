@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package licenses
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/IBM/license-scanner/normalizer"
+)
+
+// ReplacementWordsJSON is the optional file in a custom layer which adds varietal word spellings (Guideline 8.1.1)
+// to the built-in replacement words, e.g. {"license": "licence|licenze"}
+const ReplacementWordsJSON = "replacement_words.json"
+
+// loadReplacementWords merges the replacement words of the custom layers, in order, with the built-in words.
+// The patterns are validated, so an invalid file fails the library load. Without any replacement words in the
+// custom layers, the library uses the default pipeline.
+func (ll *LicenseLibrary) loadReplacementWords() error {
+	var words normalizer.ReplacementWords
+	for _, layer := range customLayers(ll.Config) {
+		f := path.Join(customDir, layer, ReplacementWordsJSON)
+		b, err := fs.ReadFile(ll.resourcesFS, f)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // optional
+			}
+			return err
+		}
+		layerWords, err := normalizer.ParseReplacementWords(b)
+		if err != nil {
+			return fmt.Errorf("replacement words %v error: %w", ll.resourceName(f), err)
+		}
+		if words == nil {
+			if words, err = normalizer.DefaultReplacementWords(); err != nil {
+				return err
+			}
+		}
+		words = words.Merge(layerWords)
+	}
+	if words == nil {
+		return nil
+	}
+
+	step, err := normalizer.ReplaceVarietalWordSpellingsStep(words)
+	if err != nil {
+		return err
+	}
+	ll.pipeline, err = normalizer.DefaultPipeline().Replace(step)
+	return err
+}
+
+// Pipeline returns the normalization pipeline of the library: the default pipeline, with the replacement words
+// of the custom layers. The patterns and the texts scanned with the library are normalized with it.
+func (ll *LicenseLibrary) Pipeline() normalizer.Pipeline {
+	if ll.pipeline == nil {
+		return normalizer.DefaultPipeline()
+	}
+	return append(normalizer.Pipeline{}, ll.pipeline...)
+}
+
+// Normalize normalizes a text with the pipeline of the library
+func (ll *LicenseLibrary) Normalize(normalizedData *normalizer.NormalizationData) error {
+	if ll.pipeline == nil {
+		return normalizedData.NormalizeText()
+	}
+	return ll.pipeline.Normalize(normalizedData)
+}
+
+// setPatternPipelines sets the library pipeline in the patterns, which are normalized when they are first used
+func (ll *LicenseLibrary) setPatternPipelines() {
+	if ll.pipeline == nil {
+		return
+	}
+	for _, l := range ll.LicenseMap {
+		for _, patterns := range [][]*PrimaryPatterns{l.PrimaryPatterns, l.AssociatedPatterns} {
+			for _, pp := range patterns {
+				pp.pipeline = ll.pipeline
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package licenses
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/IBM/license-scanner/configurer"
+	"github.com/IBM/license-scanner/normalizer"
+	"github.com/IBM/license-scanner/resources"
+)
+
+func TestLicenseLibrary_ReplacementWords(t *testing.T) {
+	layers := fstest.MapFS{
+		"custom/team/replacement_words.json":                     {Data: []byte(`{"team": "crew|squad"}`)},
+		"custom/team/license_patterns/Team-2.0/license_Team.txt": {Data: []byte("Squad licence text")},
+		"custom/legal/replacement_words.json":                    {Data: []byte(`{"team": "crew|squad|unit", "hereinafter": "hereafter"}`)},
+		"custom/invalid/replacement_words.json":                  {Data: []byte(`{"team": "crew("}`)},
+	}
+	fsys := resources.Overlay(testResourcesFS, layers)
+
+	newLibrary := func(t *testing.T, custom string) (*LicenseLibrary, error) {
+		t.Helper()
+		flagSet := configurer.NewDefaultFlags()
+		if err := flagSet.Set(configurer.CustomFlag, custom); err != nil {
+			t.Fatal(err)
+		}
+		config, err := configurer.InitConfig(flagSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ll, err := NewLicenseLibraryFS(fsys, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ll, ll.AddAll()
+	}
+	normalize := func(t *testing.T, ll *LicenseLibrary, text string) string {
+		t.Helper()
+		nd := normalizer.NormalizationData{OriginalText: text}
+		if err := ll.Normalize(&nd); err != nil {
+			t.Fatal(err)
+		}
+		return nd.NormalizedText
+	}
+
+	tests := []struct {
+		name    string
+		custom  string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "built-in words",
+			custom: "default",
+			text:   "The Crew Licence hereafter",
+			want:   "the crew license hereafter",
+		},
+		{
+			name:   "layer words are merged with the built-in words",
+			custom: "default,team",
+			text:   "The Crew Licence hereafter",
+			want:   "the team license hereafter",
+		},
+		{
+			name:   "later layers replace the same word",
+			custom: "default,team,legal",
+			text:   "The Unit Licence hereafter",
+			want:   "the team license hereinafter",
+		},
+		{
+			name:    "invalid pattern",
+			custom:  "default,invalid",
+			wantErr: "replacement words custom/invalid/replacement_words.json error: invalid pattern for replacement word \"team\"",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ll, err := newLibrary(t, tt.custom)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("AddAll() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+			if d := cmp.Diff(tt.want, normalize(t, ll, tt.text)); d != "" {
+				t.Errorf("Didn't get expected normalized text: (-want, +got): %v", d)
+			}
+		})
+	}
+
+	// The patterns are normalized with the same words as the texts
+	ll, err := newLibrary(t, "default,team")
+	if err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	re, err := GenerateMatchingPatternFromSourceText(ll.LicenseMap["Team-2.0"].PrimaryPatterns[0])
+	if err != nil {
+		t.Fatalf("GenerateMatchingPatternFromSourceText() error = %v", err)
+	}
+	if text := normalize(t, ll, "Crew license text"); !re.MatchString(text) {
+		t.Errorf("pattern %v did not match %q", re, text)
+	}
+}
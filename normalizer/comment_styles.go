@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed comment_styles.json
+var commentStylesBytes []byte
+
+// CommentStyle is the comment syntax of a language (Guideline 6.1.1).
+// When the comment style of a text is known, only its comment delimiters are removed,
+// instead of the generic comment indicators of all languages.
+type CommentStyle struct {
+	// Extensions are the lower case file extensions (e.g. ".py") or file names (e.g. "makefile") of the language
+	Extensions []string `json:"extensions,omitempty"`
+	// Interpreters are the shebang interpreters of the language (e.g. "python" for #!/usr/bin/env python3)
+	Interpreters []string `json:"interpreters,omitempty"`
+	// Line are the line comment delimiters (e.g. "#")
+	Line []string `json:"line,omitempty"`
+	// Block are the block comment delimiters
+	Block []BlockComment `json:"block,omitempty"`
+
+	name string
+	res  []*regexp.Regexp
+}
+
+// BlockComment is a pair of block comment delimiters, with an optional prefix of the lines
+// inside the block (e.g. the "*" of " * " in C)
+type BlockComment struct {
+	Begin  string `json:"begin"`
+	End    string `json:"end"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// CommentStyles are the comment styles by language name
+type CommentStyles map[string]*CommentStyle
+
+// ParseCommentStyles reads comment styles in the JSON format of comment_styles.json and validates them
+func ParseCommentStyles(b []byte) (CommentStyles, error) {
+	styles := make(CommentStyles)
+	if err := json.Unmarshal(b, &styles); err != nil {
+		return nil, err
+	}
+	for name, style := range styles {
+		if style == nil {
+			return nil, fmt.Errorf("comment style %q is empty", name)
+		}
+		if err := style.compile(name); err != nil {
+			return nil, err
+		}
+	}
+	return styles, nil
+}
+
+// DefaultCommentStyles returns the built-in comment styles
+func DefaultCommentStyles() (CommentStyles, error) {
+	return ParseCommentStyles(commentStylesBytes)
+}
+
+// Merge returns the styles with the other styles added. A style in other replaces the style of the same name,
+// and its extensions and interpreters are removed from the other styles, so that a custom style can take over
+// an extension of a built-in style.
+func (cs CommentStyles) Merge(other CommentStyles) CommentStyles {
+	claimed := make(map[string]bool)
+	for _, style := range other {
+		for _, key := range style.Extensions {
+			claimed["ext:"+key] = true
+		}
+		for _, key := range style.Interpreters {
+			claimed["int:"+key] = true
+		}
+	}
+	merged := make(CommentStyles, len(cs)+len(other))
+	for name, style := range cs {
+		s := *style
+		s.Extensions = unclaimed(style.Extensions, "ext:", claimed)
+		s.Interpreters = unclaimed(style.Interpreters, "int:", claimed)
+		merged[name] = &s
+	}
+	for name, style := range other {
+		merged[name] = style
+	}
+	return merged
+}
+
+func unclaimed(keys []string, kind string, claimed map[string]bool) []string {
+	var kept []string
+	for _, key := range keys {
+		if !claimed[kind+key] {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// Select returns the comment style of a text, by the extension or the name of the file,
+// or else by the interpreter of a shebang in the first line of the text.
+// It returns nil if the style is not known.
+func (cs CommentStyles) Select(fileName string, text string) *CommentStyle {
+	base := strings.ToLower(path.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	ext := path.Ext(base)
+	interpreter := shebangInterpreter(text)
+	var byName, byExt, byInterpreter *CommentStyle
+	for _, name := range cs.names() {
+		style := cs[name]
+		for _, e := range style.Extensions {
+			if byName == nil && base != "" && e == base {
+				byName = style
+			}
+			if byExt == nil && ext != "" && e == ext {
+				byExt = style
+			}
+		}
+		for _, i := range style.Interpreters {
+			if byInterpreter == nil && interpreter != "" && i == interpreter {
+				byInterpreter = style
+			}
+		}
+	}
+	switch {
+	case byName != nil:
+		return byName
+	case byExt != nil:
+		return byExt
+	default:
+		return byInterpreter
+	}
+}
+
+// Name returns the language name of the style
+func (s *CommentStyle) Name() string {
+	return s.name
+}
+
+func (cs CommentStyles) names() []string {
+	names := make([]string, 0, len(cs))
+	for name := range cs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// shebangInterpreter returns the interpreter of a #! line without its version, e.g. "python" for
+// "#!/usr/bin/env python3.11"
+func shebangInterpreter(text string) string {
+	if !strings.HasPrefix(text, "#!") {
+		return ""
+	}
+	line := text[2:]
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				interpreter = f
+				break
+			}
+		}
+	}
+	return strings.TrimRight(strings.ToLower(interpreter), "0123456789.")
+}
+
+// compile validates the delimiters and compiles the regexps which remove them, in order:
+// the block delimiters, the prefixes inside blocks, and the line delimiters.
+// The text is lower case when comments are removed, so the delimiters are lower cased.
+func (s *CommentStyle) compile(name string) error {
+	if len(s.Line) == 0 && len(s.Block) == 0 {
+		return fmt.Errorf("comment style %q has no comment delimiters", name)
+	}
+	var outside, inside, line []string
+	for _, b := range s.Block {
+		if b.Begin == "" || b.End == "" {
+			return fmt.Errorf("comment style %q has a block comment without begin and end delimiters", name)
+		}
+		outside = append(outside, `^\s*`+delimiterPattern(b.Begin), delimiterPattern(b.End)+`\s*$`)
+		if b.Prefix != "" {
+			inside = append(inside, `^\s*(?:`+delimiterPattern(b.Prefix)+`)+`)
+		}
+	}
+	for _, l := range s.Line {
+		if l == "" {
+			return fmt.Errorf("comment style %q has an empty line comment delimiter", name)
+		}
+		line = append(line, `^\s*(?:`+delimiterPattern(l)+`)+`)
+	}
+	s.name = name
+	s.res = nil
+	for _, alternatives := range [][]string{outside, inside, line} {
+		if len(alternatives) > 0 {
+			s.res = append(s.res, regexp.MustCompile(`(?m)`+strings.Join(alternatives, "|")))
+		}
+	}
+	return nil
+}
+
+// delimiterPattern quotes a delimiter. A delimiter which ends with a letter (e.g. REM) must be a whole word.
+func delimiterPattern(delimiter string) string {
+	delimiter = strings.ToLower(delimiter)
+	p := regexp.QuoteMeta(delimiter)
+	if r := []rune(delimiter); unicode.IsLetter(r[len(r)-1]) || unicode.IsDigit(r[len(r)-1]) {
+		p += `\b`
+	}
+	return p
+}
+
+// removeCommentDelimiters removes the comment delimiters of the style
+func (n *NormalizationData) removeCommentDelimiters(s *CommentStyle) {
+	for _, re := range s.res {
+		n.RegexpReplacePatternAndUpdateIndexMap(re, " ")
+	}
+}
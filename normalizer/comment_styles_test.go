@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package normalizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommentStyles_Select(t *testing.T) {
+	t.Parallel()
+	styles, err := DefaultCommentStyles()
+	if err != nil {
+		t.Fatalf("DefaultCommentStyles() error = %v", err)
+	}
+
+	tcs := []struct {
+		name     string
+		fileName string
+		text     string
+		expected string
+	}{
+		{name: "extension", fileName: "src/app/main.py", expected: "python"},
+		{name: "upper case extension", fileName: `C:\src\SETUP.BAT`, expected: "batch"},
+		{name: "file name", fileName: "build/Makefile", expected: "shell"},
+		{name: "shebang", fileName: "bin/run", text: "#!/usr/bin/perl -w\n", expected: "perl"},
+		{name: "env shebang with version", text: "#!/usr/bin/env python3.11\n", expected: "python"},
+		{name: "extension before shebang", fileName: "run.rb", text: "#!/bin/sh\n", expected: "ruby"},
+		{name: "f# is not ocaml", fileName: "Library.fsx", expected: "fsharp"},
+		{name: "ini is not lisp", fileName: "setup.ini", expected: "ini"},
+		{name: "php is not c", fileName: "index.php", expected: "php"},
+		{name: "unknown", fileName: "LICENSE", text: "MIT License", expected: ""},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := ""
+			if style := styles.Select(tc.fileName, tc.text); style != nil {
+				got = style.Name()
+			}
+			if d := cmp.Diff(tc.expected, got); d != "" {
+				t.Errorf("Didn't get expected comment style: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestCommentStyles_Merge(t *testing.T) {
+	t.Parallel()
+	styles, err := DefaultCommentStyles()
+	if err != nil {
+		t.Fatalf("DefaultCommentStyles() error = %v", err)
+	}
+	custom, err := ParseCommentStyles([]byte(`{"objc": {"extensions": [".m"], "line": ["//"]}}`))
+	if err != nil {
+		t.Fatalf("ParseCommentStyles() error = %v", err)
+	}
+	merged := styles.Merge(custom)
+	if d := cmp.Diff("objc", merged.Select("main.m", "").Name()); d != "" {
+		t.Errorf("Didn't get expected comment style: (-want, +got): %v", d)
+	}
+	if d := cmp.Diff("c", styles.Select("main.m", "").Name()); d != "" {
+		t.Errorf("Merge() changed the merged styles: (-want, +got): %v", d)
+	}
+}
+
+func TestParseCommentStyles_Invalid(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{name: "no delimiters", json: `{"x": {"extensions": [".x"]}}`, expected: `comment style "x" has no comment delimiters`},
+		{name: "block without end", json: `{"x": {"block": [{"begin": "(*"}]}}`, expected: `comment style "x" has a block comment without begin and end delimiters`},
+		{name: "empty line delimiter", json: `{"x": {"line": [""]}}`, expected: `comment style "x" has an empty line comment delimiter`},
+		{name: "null style", json: `{"x": null}`, expected: `comment style "x" is empty`},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseCommentStyles([]byte(tc.json))
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("ParseCommentStyles() error = %v, want %v", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizationData_NormalizeText_CommentStyle(t *testing.T) {
+	t.Parallel()
+	styles, err := DefaultCommentStyles()
+	if err != nil {
+		t.Fatalf("DefaultCommentStyles() error = %v", err)
+	}
+
+	tcs := []struct {
+		name     string
+		fileName string
+		text     string
+		expected string
+	}{
+		{
+			name:     "python docstring",
+			fileName: "mod.py",
+			text:     "\"\"\"\nCopyright 2020 ACME\nLicensed under the MIT License.\n\"\"\"\n# more\nimport os",
+			expected: "copyright 2020 acme licensed under the mit license. more import os",
+		},
+		{
+			name:     "batch REM",
+			fileName: "setup.bat",
+			text:     "@REM Copyright 2020 ACME\nREM Licensed under the MIT License.\nREMOVE temp",
+			expected: "copyright 2020 acme licensed under the mit license. remove temp",
+		},
+		{
+			name:     "latex percent",
+			fileName: "paper.tex",
+			text:     "%% Copyright 2020 ACME\n% Licensed under the MIT License.",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "ocaml block",
+			fileName: "main.ml",
+			text:     "(*\n * Copyright 2020 ACME\n * Licensed under the MIT License.\n *)",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "f# line and block",
+			fileName: "Library.fs",
+			text:     "// Copyright 2020 ACME\n(* Licensed under the MIT License. *)",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "ini semicolon and hash",
+			fileName: "settings.ini",
+			text:     "; Copyright 2020 ACME\n# Licensed under the MIT License.",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "assembly",
+			fileName: "start.s",
+			text:     "/*\n * Copyright 2020 ACME\n */\n# Licensed under\n// the MIT License.",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "haskell block and line",
+			fileName: "Main.hs",
+			text:     "{-\nCopyright 2020 ACME\n-}\n-- Licensed under the MIT License.",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "visual basic quote",
+			fileName: "Module1.vb",
+			text:     "' Copyright 2020 ACME\n' Licensed under the MIT License.",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+		{
+			name:     "only the delimiters of the language are removed",
+			fileName: "main.c",
+			text:     "// Copyright 2020 ACME\n; Licensed under the MIT License.",
+			expected: "copyright 2020 acme ; licensed under the mit license.",
+		},
+		{
+			name:     "generic comment indicators without a style",
+			fileName: "LICENSE",
+			text:     "// Copyright 2020 ACME\n; Licensed under the MIT License.",
+			expected: "copyright 2020 acme licensed under the mit license.",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			n := NormalizationData{OriginalText: tc.text, CommentStyle: styles.Select(tc.fileName, tc.text)}
+			if err := n.NormalizeText(); err != nil {
+				t.Fatalf("NormalizeText() error = %v", err)
+			}
+			if d := cmp.Diff(tc.expected, strings.TrimSpace(n.NormalizedText)); d != "" {
+				t.Errorf("Didn't get expected normalized text: (-want, +got): %v", d)
+			}
+			for i, idx := range n.IndexMap {
+				if idx >= 0 && n.NormalizedText[i] != ' ' && strings.ToLower(tc.text)[idx] != n.NormalizedText[i] {
+					t.Errorf("IndexMap[%v] = %v points to %q, want %q", i, idx, tc.text[idx], n.NormalizedText[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Encodings detected by DecodeText
+const (
+	EncodingUTF8        = "UTF-8"
+	EncodingUTF16LE     = "UTF-16LE"
+	EncodingUTF16BE     = "UTF-16BE"
+	EncodingWindows1252 = "windows-1252"
+	EncodingISO88591    = "ISO-8859-1"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DecodedText is a file decoded to UTF-8
+type DecodedText struct {
+	Text     string
+	Encoding string
+	// ByteOffsets maps each byte of the Text to the offset of its encoded character in the file.
+	// It is nil when the Text is the file (UTF-8 without a byte order mark).
+	ByteOffsets []int
+}
+
+// FileOffset returns the offset in the file of the character at an offset in the Text
+func (d DecodedText) FileOffset(textOffset int) int {
+	return FileOffset(d.ByteOffsets, textOffset)
+}
+
+// FileOffset returns the offset in a file of the character at an offset in its decoded text (see DecodedText.ByteOffsets)
+func FileOffset(byteOffsets []int, textOffset int) int {
+	if byteOffsets == nil || textOffset < 0 || textOffset >= len(byteOffsets) {
+		return textOffset
+	}
+	return byteOffsets[textOffset]
+}
+
+// DecodeText detects the encoding of a file (see DetectEncoding) and decodes it to UTF-8
+func DecodeText(b []byte) DecodedText {
+	return DecodeTextAs(b, DetectEncoding(b))
+}
+
+// DetectEncoding detects the encoding of a file from its start, which may end within a character.
+// A byte order mark selects UTF-8, UTF-16LE or UTF-16BE. Without one, UTF-16 is detected by its zero bytes,
+// valid UTF-8 is UTF-8, and other texts are Windows-1252 if they have any byte in 0x80-0x9F
+// (which are control characters in ISO-8859-1), or else ISO-8859-1.
+func DetectEncoding(b []byte) string {
+	switch {
+	case bytes.HasPrefix(b, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(b, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(b, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+	if bigEndian, ok := detectUTF16(b); ok {
+		if bigEndian {
+			return EncodingUTF16BE
+		}
+		return EncodingUTF16LE
+	}
+	if utf8.Valid(trimPartialRune(b)) {
+		return EncodingUTF8
+	}
+	return singleByteEncoding(b)
+}
+
+// singleByteEncoding returns Windows-1252 if a text has any byte in 0x80-0x9F, or else ISO-8859-1
+func singleByteEncoding(b []byte) string {
+	for _, c := range b {
+		if c >= 0x80 && c <= 0x9F {
+			return EncodingWindows1252
+		}
+	}
+	return EncodingISO88591
+}
+
+// DecodePart decodes a part of a file, which starts at an even offset in the file, with the encoding of the file
+// detected by DetectEncoding at its start, or by the previous parts. A part of a UTF-16 file is decoded as UTF-16,
+// and a part of a single byte encoded file as Windows-1252 or ISO-8859-1. The encoding of a part of a UTF-8 file is
+// detected from the part, since a single byte encoded file may only be ASCII at its start, and the bytes of the
+// UTF-8 characters which begin before the part or end after it are trimmed. The ByteOffsets are offsets in the part.
+func DecodePart(b []byte, fileEncoding string) DecodedText {
+	switch fileEncoding {
+	case EncodingUTF16LE, EncodingUTF16BE, EncodingWindows1252:
+		return DecodeTextAs(b, fileEncoding)
+	case EncodingISO88591:
+		return DecodeTextAs(b, singleByteEncoding(b))
+	}
+	start := 0
+	for start < len(b) && start < utf8.UTFMax-1 && !utf8.RuneStart(b[start]) {
+		start++
+	}
+	part := trimPartialRune(b[start:])
+	if !utf8.Valid(part) {
+		return DecodeTextAs(b, singleByteEncoding(b))
+	}
+	if start == 0 {
+		return DecodeTextAs(part, EncodingUTF8)
+	}
+	text, offsets := decodeUTF8(part, start)
+	return DecodedText{Text: text, Encoding: EncodingUTF8, ByteOffsets: offsets}
+}
+
+// DecodeTextAs decodes a file, or a part of a file, with an encoding returned by DetectEncoding.
+// A byte order mark is skipped. A part of a UTF-16 file must start at an even offset in the file.
+func DecodeTextAs(b []byte, encoding string) DecodedText {
+	switch encoding {
+	case EncodingUTF16LE:
+		if bytes.HasPrefix(b, bomUTF16LE) {
+			return decodeUTF16(b, len(bomUTF16LE), false)
+		}
+		return decodeUTF16(b, 0, false)
+	case EncodingUTF16BE:
+		if bytes.HasPrefix(b, bomUTF16BE) {
+			return decodeUTF16(b, len(bomUTF16BE), true)
+		}
+		return decodeUTF16(b, 0, true)
+	case EncodingWindows1252:
+		return decodeSingleByte(b, EncodingWindows1252, charmap.Windows1252.DecodeByte)
+	case EncodingISO88591:
+		return decodeSingleByte(b, EncodingISO88591, func(c byte) rune { return rune(c) })
+	default:
+		if bytes.HasPrefix(b, bomUTF8) {
+			text, offsets := decodeUTF8(b[len(bomUTF8):], len(bomUTF8))
+			return DecodedText{Text: text, Encoding: EncodingUTF8, ByteOffsets: offsets}
+		}
+		return DecodedText{Text: string(b), Encoding: EncodingUTF8}
+	}
+}
+
+// trimPartialRune trims the start of a UTF-8 character at the end of a text
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// detectUTF16 detects UTF-16 without a byte order mark: most code units of the start of the text
+// are ASCII characters, with a zero high byte
+func detectUTF16(b []byte) (bigEndian bool, ok bool) {
+	const sample = 1024
+	n := len(b) &^ 1
+	if n > sample {
+		n = sample
+	}
+	if n < 4 {
+		return false, false
+	}
+	var le, be int
+	for i := 0; i < n; i += 2 {
+		if b[i] != 0 && b[i+1] == 0 {
+			le++
+		}
+		if b[i] == 0 && b[i+1] != 0 {
+			be++
+		}
+	}
+	units := n / 2
+	switch {
+	case le*10 >= units*9:
+		return false, true
+	case be*10 >= units*9:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func decodeUTF8(b []byte, start int) (string, []int) {
+	offsets := make([]int, len(b))
+	for i := range offsets {
+		offsets[i] = start + i
+	}
+	return string(b), offsets
+}
+
+func decodeUTF16(b []byte, start int, bigEndian bool) DecodedText {
+	encoding := EncodingUTF16LE
+	if bigEndian {
+		encoding = EncodingUTF16BE
+	}
+	unit := func(i int) uint16 {
+		if bigEndian {
+			return uint16(b[i])<<8 | uint16(b[i+1])
+		}
+		return uint16(b[i+1])<<8 | uint16(b[i])
+	}
+	var text strings.Builder
+	text.Grow(len(b) / 2)
+	offsets := make([]int, 0, len(b)/2)
+	for i := start; i+1 < len(b); i += 2 {
+		r, offset := rune(unit(i)), i
+		if utf16.IsSurrogate(r) && i+3 < len(b) {
+			if pair := utf16.DecodeRune(r, rune(unit(i+2))); pair != utf8.RuneError {
+				r = pair
+				i += 2
+			}
+		}
+		n, _ := text.WriteRune(r)
+		for j := 0; j < n; j++ {
+			offsets = append(offsets, offset)
+		}
+	}
+	return DecodedText{Text: text.String(), Encoding: encoding, ByteOffsets: offsets}
+}
+
+func decodeSingleByte(b []byte, encoding string, decode func(byte) rune) DecodedText {
+	var text strings.Builder
+	text.Grow(len(b) + len(b)/8)
+	offsets := make([]int, 0, len(b)+len(b)/8)
+	for i, c := range b {
+		n, _ := text.WriteRune(decode(c))
+		for j := 0; j < n; j++ {
+			offsets = append(offsets, i)
+		}
+	}
+	return DecodedText{Text: text.String(), Encoding: encoding, ByteOffsets: offsets}
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package normalizer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeText(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name     string
+		input    []byte
+		expected DecodedText
+	}{
+		{
+			name:     "UTF-8",
+			input:    []byte("MIT ©"),
+			expected: DecodedText{Text: "MIT ©", Encoding: EncodingUTF8},
+		},
+		{
+			name:     "UTF-8 with BOM",
+			input:    []byte("\xEF\xBB\xBFMIT ©"),
+			expected: DecodedText{Text: "MIT ©", Encoding: EncodingUTF8, ByteOffsets: []int{3, 4, 5, 6, 7, 8}},
+		},
+		{
+			name:     "UTF-16LE with BOM",
+			input:    []byte{0xFF, 0xFE, 'M', 0, 'I', 0, 0xA9, 0},
+			expected: DecodedText{Text: "MI©", Encoding: EncodingUTF16LE, ByteOffsets: []int{2, 4, 6, 6}},
+		},
+		{
+			name:     "UTF-16BE with BOM and a surrogate pair",
+			input:    []byte{0xFE, 0xFF, 0, 'M', 0xD8, 0x3D, 0xDE, 0x00, 0, 'I'},
+			expected: DecodedText{Text: "M\U0001F600I", Encoding: EncodingUTF16BE, ByteOffsets: []int{2, 4, 4, 4, 4, 8}},
+		},
+		{
+			name:     "UTF-16LE without BOM",
+			input:    []byte{'M', 0, 'I', 0, 'T', 0},
+			expected: DecodedText{Text: "MIT", Encoding: EncodingUTF16LE, ByteOffsets: []int{0, 2, 4}},
+		},
+		{
+			name:     "UTF-16BE without BOM",
+			input:    []byte{0, 'M', 0, 'I', 0, 'T'},
+			expected: DecodedText{Text: "MIT", Encoding: EncodingUTF16BE, ByteOffsets: []int{0, 2, 4}},
+		},
+		{
+			name:     "Windows-1252",
+			input:    []byte("\x93MIT\x94 \xA9"),
+			expected: DecodedText{Text: "“MIT” ©", Encoding: EncodingWindows1252, ByteOffsets: []int{0, 0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 6}},
+		},
+		{
+			name:     "ISO-8859-1",
+			input:    []byte("Se\xF1or \xA9"),
+			expected: DecodedText{Text: "Señor ©", Encoding: EncodingISO88591, ByteOffsets: []int{0, 1, 2, 2, 3, 4, 5, 6, 6}},
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tc.expected, DecodeText(tc.input)); d != "" {
+				t.Errorf("Didn't get expected decoded text: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestDecodedText_FileOffset(t *testing.T) {
+	t.Parallel()
+	utf16 := DecodeText([]byte{0xFF, 0xFE, 'M', 0, 'I', 0, 'T', 0})
+	utf8 := DecodeText([]byte("MIT"))
+	tcs := []struct {
+		name     string
+		decoded  DecodedText
+		offset   int
+		expected int
+	}{
+		{name: "UTF-16", decoded: utf16, offset: 2, expected: 6},
+		{name: "UTF-16 out of range", decoded: utf16, offset: 3, expected: 3},
+		{name: "UTF-8", decoded: utf8, offset: 2, expected: 2},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tc.expected, tc.decoded.FileOffset(tc.offset)); d != "" {
+				t.Errorf("Didn't get expected file offset: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{name: "UTF-8 ending within a character", input: []byte("MIT ©")[:5], expected: EncodingUTF8},
+		{name: "invalid UTF-8 before the end", input: []byte("MIT \xC2 "), expected: EncodingISO88591},
+		{name: "UTF-16LE part without BOM", input: []byte{0xA9, 0, 'M', 0, 'I', 0, 'T', 0}, expected: EncodingUTF16LE},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tc.expected, DetectEncoding(tc.input)); d != "" {
+				t.Errorf("Didn't get expected encoding: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestDecodeTextAs(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name     string
+		input    []byte
+		encoding string
+		expected DecodedText
+	}{
+		{
+			name:     "UTF-8 part",
+			input:    []byte("MIT ©")[:5],
+			encoding: EncodingUTF8,
+			expected: DecodedText{Text: "MIT \xC2", Encoding: EncodingUTF8},
+		},
+		{
+			name:     "UTF-16LE part ending within a surrogate pair",
+			input:    []byte{0xA9, 0, 'M', 0, 0x3D, 0xD8},
+			encoding: EncodingUTF16LE,
+			expected: DecodedText{Text: "©M\uFFFD", Encoding: EncodingUTF16LE, ByteOffsets: []int{0, 0, 2, 4, 4, 4}},
+		},
+		{
+			name:     "Windows-1252 part",
+			input:    []byte("MIT\x94"),
+			encoding: EncodingWindows1252,
+			expected: DecodedText{Text: "MIT”", Encoding: EncodingWindows1252, ByteOffsets: []int{0, 1, 2, 3, 3, 3}},
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tc.expected, DecodeTextAs(tc.input, tc.encoding)); d != "" {
+				t.Errorf("Didn't get expected decoded text: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestDecodePart(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name         string
+		input        []byte
+		fileEncoding string
+		expected     DecodedText
+	}{
+		{
+			name:         "UTF-8 part within characters",
+			input:        []byte("é MIT ©")[1:8],
+			fileEncoding: EncodingUTF8,
+			expected:     DecodedText{Text: " MIT ", Encoding: EncodingUTF8, ByteOffsets: []int{1, 2, 3, 4, 5}},
+		},
+		{
+			name:         "single byte part of a file with an ASCII start",
+			input:        []byte("MIT\xA9"),
+			fileEncoding: EncodingUTF8,
+			expected:     DecodedText{Text: "MIT©", Encoding: EncodingISO88591, ByteOffsets: []int{0, 1, 2, 3, 3}},
+		},
+		{
+			name:         "Windows-1252 part of an ISO-8859-1 file",
+			input:        []byte("\x93MIT"),
+			fileEncoding: EncodingISO88591,
+			expected:     DecodedText{Text: "“MIT", Encoding: EncodingWindows1252, ByteOffsets: []int{0, 0, 0, 1, 2, 3}},
+		},
+		{
+			name:         "ISO-8859-1 part starting with a continuation byte",
+			input:        []byte("\xA9 MIT"),
+			fileEncoding: EncodingISO88591,
+			expected:     DecodedText{Text: "© MIT", Encoding: EncodingISO88591, ByteOffsets: []int{0, 0, 1, 2, 3, 4}},
+		},
+		{
+			name:         "UTF-16 part",
+			input:        []byte{'M', 0, 0xA9, 0},
+			fileEncoding: EncodingUTF16LE,
+			expected:     DecodedText{Text: "M©", Encoding: EncodingUTF16LE, ByteOffsets: []int{0, 2, 2}},
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tc.expected, DecodePart(tc.input, tc.fileEncoding)); d != "" {
+				t.Errorf("Didn't get expected decoded text: (-want, +got): %v", d)
+			}
+		})
+	}
+}
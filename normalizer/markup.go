@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Markup languages of license texts, e.g. LICENSE.md or LICENSE.rst
+const (
+	MarkupMarkdown = "markdown"
+	MarkupRST      = "rst"
+)
+
+var markupExtensions = map[string]string{
+	".md":       MarkupMarkdown,
+	".markdown": MarkupMarkdown,
+	".mdown":    MarkupMarkdown,
+	".mkd":      MarkupMarkdown,
+	".rst":      MarkupRST,
+	".rest":     MarkupRST,
+}
+
+// Markup detection. A strong signal detects the markup by itself, or else two kinds of weak signals are needed.
+// Plain license texts often have <url>, `quoted' words and _____ blanks, so these are not signals.
+var (
+	markdownStrongREs = []*regexp.Regexp{
+		regexp.MustCompile(`\[[^\[\]]+\]\((?:https?://|#|\.{0,2}/)[^)\s]*\)`), // [text](url)
+		regexp.MustCompile("(?m)^[ \t]*(?:```|~~~)"),                          // code fence
+	}
+	markdownWeakREs = []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^#{1,6}[ \t]+\w`),
+		regexp.MustCompile(`\*\*\w[^*\n]*\w\*\*`),
+		regexp.MustCompile(`(?m)^>[ \t]+\w`),
+	}
+	rstStrongREs = []*regexp.Regexp{
+		regexp.MustCompile("`[^`<\n]*<[^>\n]+>`__?"),        // `text <url>`_
+		regexp.MustCompile(`(?m)^\.\.[ \t]+(?:[\w-]+::|_)`), // directive or target
+	}
+	rstWeakREs = []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\w[^\n]*\n(?:={3,}|-{3,}|~{3,}|\^{3,})[ \t]*$`), // underlined title
+		regexp.MustCompile("``[^`'\\s][^`'\n]*``"),
+	}
+)
+
+// Markdown formatting. Patterns with submatches remove the submatches (the markup around the text),
+// and patterns without submatches replace the whole match with a space.
+var markdownREs = []*regexp.Regexp{
+	regexp.MustCompile("(?m)^[ \t]*(?:```|~~~)[^\n]*$"),                            // code fences
+	regexp.MustCompile(`(?m)^[ \t]{0,3}\[[^\]\n]+\]:[ \t]*\S+[^\n]*$`),             // link reference definitions
+	regexp.MustCompile(`(!\[)[^\[\]]*(\]\([^)\s]*\))`),                             // images keep the alt text
+	regexp.MustCompile(`(\[)[^\[\]]+(\]\([^)\s]*\))`),                              // inline links keep the text
+	regexp.MustCompile(`(\[)[^\[\]]+(\]\[[^\]\n]*\])`),                             // reference links keep the text
+	regexp.MustCompile(`(?m)^[ \t]{0,3}(#{1,6})[ \t][^\n]*?(?:[ \t](#+))?[ \t]*$`), // ATX headings
+	regexp.MustCompile(`(?m)^[ \t]{0,3}((?:>[ \t]?)+)`),                            // block quotes
+	regexp.MustCompile(`(\*\*)[^\s*](?:[^\n]*?[^\s*])?(\*\*)`),
+	regexp.MustCompile(`(?:^|\W)(__)[^\s_](?:[^\n]*?[^\s_])?(__)(?:\W|$)`),
+	regexp.MustCompile(`(\*)[^\s*](?:[^*\n]*?[^\s*])?(\*)`),
+	regexp.MustCompile(`(?:^|\W)(_)[^\s_](?:[^_\n]*?[^\s_])?(_)(?:\W|$)`),
+	regexp.MustCompile("(`+)[^`\n]+?(`+)"),                 // inline code
+	regexp.MustCompile("(\\\\)[\\\\`*_{}\\[\\]()#+.!<>-]"), // backslash escapes
+}
+
+// reStructuredText formatting, in the same form as markdownREs
+var rstREs = []*regexp.Regexp{
+	// section adornments
+	regexp.MustCompile(`(?m)^[ \t]*(?:={3,}|-{3,}|~{3,}|\^{3,}|"{3,}|'{3,}|` + "`{3,}" + `|#{3,}|\*{3,}|\+{3,}|:{3,}|\.{3,}|_{3,})[ \t]*$`),
+	regexp.MustCompile(`(?m)^[ \t]*\.\.[ \t]+_[^:\n]+:[^\n]*$`),    // hyperlink targets
+	regexp.MustCompile(`(?m)^[ \t]*(\.\.[ \t]+[\w-]+::)`),          // directives keep the arguments
+	regexp.MustCompile(`(?m)^[ \t]*(\.\.)(?:[ \t]|$)`),             // comments keep the text
+	regexp.MustCompile("(`)[^`<\n]*[^`<\\s][ \t]*(<[^>\n]+>`__?)"), // `text <url>`_ keeps the text
+	regexp.MustCompile("(`<)[^>\n]+(>`__?)"),                       // `<url>`_ keeps the URL
+	regexp.MustCompile("(:[\\w-]+:`)[^`\n]+(`)"),                   // roles, e.g. :ref:`text`
+	regexp.MustCompile("(``)[^`\n]+(``)"),                          // inline literals
+	regexp.MustCompile("(`)[^`\n]+(`__?)"),                         // references
+	regexp.MustCompile("(`)[^`\n]+(`)"),                            // interpreted text
+	regexp.MustCompile(`(\*\*)[^\s*](?:[^\n]*?[^\s*])?(\*\*)`),
+	regexp.MustCompile(`(\*)[^\s*](?:[^*\n]*?[^\s*])?(\*)`),
+	regexp.MustCompile(`\w(__?)(?:[^\w]|$)`),   // word references, e.g. license_
+	regexp.MustCompile(`(?m):(:)[ \t]*$`),      // literal block markers
+	regexp.MustCompile(`(?m)^[ \t]*(\|)[ \t]`), // line blocks
+}
+
+// DetectMarkup returns the markup language of a text by the extension of the file name.
+// Texts without an extension or with a .txt extension (e.g. LICENSE or LICENSE.txt) are detected by their formatting,
+// except scripts with a shebang.
+// It returns "" for plain text.
+func DetectMarkup(fileName string, text string) string {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(fileName, "\\", "/")))
+	if markup, ok := markupExtensions[ext]; ok {
+		return markup
+	}
+	if ext != "" && ext != ".txt" || strings.HasPrefix(text, "#!") {
+		return ""
+	}
+	switch {
+	case detected(text, markdownStrongREs, markdownWeakREs):
+		return MarkupMarkdown
+	case detected(text, rstStrongREs, rstWeakREs):
+		return MarkupRST
+	default:
+		return ""
+	}
+}
+
+func detected(text string, strong []*regexp.Regexp, weak []*regexp.Regexp) bool {
+	for _, re := range strong {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	found := 0
+	for _, re := range weak {
+		if re.MatchString(text) {
+			found++
+		}
+	}
+	return found >= 2
+}
+
+// removeMarkup removes the formatting of the markup language of the text (e.g. headings, emphasis and link syntax),
+// and keeps the text
+func (n *NormalizationData) removeMarkup() {
+	switch n.Markup {
+	case MarkupMarkdown:
+		n.removeFormatting(markdownREs)
+	case MarkupRST:
+		n.removeFormatting(rstREs)
+	}
+}
+
+// removeFormatting removes the submatches of each pattern, or replaces the whole match with a space.
+// In templates, the markup inside the <<regex>> of the replaceable text and omitable tags is kept.
+func (n *NormalizationData) removeFormatting(res []*regexp.Regexp) {
+	for _, re := range res {
+		var protected [][]int
+		if n.IsTemplate {
+			protected = templateTagRE.FindAllStringIndex(n.NormalizedText, -1)
+		}
+		var removed [][]int
+		var replacements []string
+		for _, match := range re.FindAllStringSubmatchIndex(n.NormalizedText, -1) {
+			spans, replacement := match[2:], ""
+			if re.NumSubexp() == 0 {
+				spans, replacement = match[:2], " "
+			}
+			for i := 0; i < len(spans); i += 2 {
+				if spans[i] >= 0 && spans[i+1] > spans[i] && !overlaps(protected, spans[i], spans[i+1]) {
+					removed = append(removed, []int{spans[i], spans[i+1]})
+					replacements = append(replacements, replacement)
+				}
+			}
+		}
+		if removed != nil {
+			n.ReplaceMatchesWithStringsAndUpdateIndexMap(removed, replacements)
+		}
+	}
+}
+
+// templateTagRE matches the tags of a template after captureReplaceableTextSections and standardizeOmitableTags
+var templateTagRE = regexp.MustCompile(`<<.*?>>`)
+
+func overlaps(spans [][]int, from int, to int) bool {
+	for _, span := range spans {
+		if from < span[1] && to > span[0] {
+			return true
+		}
+	}
+	return false
+}
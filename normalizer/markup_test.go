@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package normalizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectMarkup(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name     string
+		fileName string
+		text     string
+		expected string
+	}{
+		{name: "markdown extension", fileName: "LICENSE.md", text: "MIT License", expected: MarkupMarkdown},
+		{name: "rst extension", fileName: "docs/LICENSE.RST", text: "MIT License", expected: MarkupRST},
+		{name: "markdown link", fileName: "LICENSE", text: "See [the terms](https://example.com).", expected: MarkupMarkdown},
+		{name: "markdown heading and bold", fileName: "LICENSE.txt", text: "# MIT\n\n**Copyright** ACME", expected: MarkupMarkdown},
+		{name: "rst link", text: "See `the terms <https://example.com>`_.", expected: MarkupRST},
+		{name: "rst title and literal", text: "MIT License\n===========\n\nUse ``pip``.", expected: MarkupRST},
+		{name: "plain text with a url and quotes", text: "Copyright <https://fsf.org/>\ntype `show w'.\nSigned: _____", expected: ""},
+		{name: "heading alone", text: "# MIT\nCopyright ACME", expected: ""},
+		{name: "source file", fileName: "main.py", text: "# [link](https://example.com)", expected: ""},
+		{name: "shebang", text: "#!/bin/sh\n# [link](https://example.com)", expected: ""},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if d := cmp.Diff(tc.expected, DetectMarkup(tc.fileName, tc.text)); d != "" {
+				t.Errorf("Didn't get expected markup: (-want, +got): %v", d)
+			}
+		})
+	}
+}
+
+func TestNormalizationData_NormalizeText_removeMarkup(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name     string
+		markup   string
+		text     string
+		expected string
+		// word is a word of the normalized text which is mapped to the same word in the original text
+		word string
+	}{
+		{
+			name:     "markdown",
+			markup:   MarkupMarkdown,
+			text:     "## The MIT License ##\n\n**Copyright** (c) 2020 [ACME\nCorp](https://acme.example)\n\n> Use *this* [`software`](#software)\n> for _any_ purpose.\n\n```\nfoo\\_bar\n```\n\n[1]: https://acme.example",
+			expected: "the mit license copyright copyright 2020 acme corp use this software for any purpose. foo_bar",
+			word:     "software",
+		},
+		{
+			name:     "markdown keeps plain text",
+			markup:   MarkupMarkdown,
+			text:     "Signed: _____ <https://fsf.org/> snake_case 2 * 3 * 4",
+			expected: "signed:_____ <http://fsf.org/> snake_case 2 * 3 * 4",
+			word:     "snake_case",
+		},
+		{
+			name:     "rst",
+			markup:   MarkupRST,
+			text:     "===========\nMIT License\n===========\n\n.. note:: **Copyright** 2020 ACME\n\nSee `the site <https://acme.example>`_ and ``LICENSE``::\n\n.. _site: https://acme.example\n\n| *Use* license_ freely.",
+			expected: "mit license copyright 2020 acme see the site and license:use license freely.",
+			word:     "freely",
+		},
+		{
+			name:     "plain text",
+			markup:   "",
+			text:     "**Copyright** [ACME](https://acme.example)",
+			expected: "copyright [acme](http://acme.example)",
+			word:     "acme",
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			n := NormalizationData{OriginalText: tc.text, Markup: tc.markup}
+			if err := n.NormalizeText(); err != nil {
+				t.Fatalf("NormalizeText() error = %v", err)
+			}
+			if d := cmp.Diff(tc.expected, strings.TrimSpace(n.NormalizedText)); d != "" {
+				t.Errorf("Didn't get expected normalized text: (-want, +got): %v", d)
+			}
+			want := strings.Index(strings.ToLower(tc.text), tc.word)
+			if got := n.IndexMap[strings.Index(n.NormalizedText, tc.word)]; got != want {
+				t.Errorf("IndexMap of %q = %v, want %v", tc.word, got, want)
+			}
+		})
+	}
+}
+
+func TestNewNormalizationData_template(t *testing.T) {
+	t.Parallel()
+	template := `<<var;name="title";original="# License";match="(# )?License">>` + "\n\n**Use** it under [these terms](#terms).\n"
+	n := NewNormalizationData(template, true)
+	if d := cmp.Diff(MarkupMarkdown, n.Markup); d != "" {
+		t.Errorf("Didn't get expected markup: (-want, +got): %v", d)
+	}
+	if err := n.NormalizeText(); err != nil {
+		t.Fatalf("NormalizeText() error = %v", err)
+	}
+	// The markup in the <<regex>> of the replaceable text is kept
+	if d := cmp.Diff("<<(# )?license>> use it under these terms.", strings.TrimSpace(n.NormalizedText)); d != "" {
+		t.Errorf("Didn't get expected normalized text: (-want, +got): %v", d)
+	}
+}
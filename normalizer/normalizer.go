@@ -3,19 +3,12 @@
 package normalizer
 
 import (
-	"crypto/md5" //nolint:gosec
-	"crypto/sha256"
-	"crypto/sha512"
-	_ "embed"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
+	"html"
 	"regexp"
 	"strings"
 	"sync"
 
 	"github.com/mrutkows/sbom-utility/log"
-	"golang.org/x/exp/slices"
 )
 
 const (
@@ -43,16 +36,23 @@ const (
 	SplitWords                 = `(?m)\b-$\s+\b`
 	HorizontalRulePattern      = `(?m)^\s*[*=-]{3,}`
 	Copyright                  = `©|\([cC]\)`
+	HTMLEntityPattern          = `&(?:#[0-9]{1,7}|#x[0-9a-f]{1,6}|[a-z][a-z0-9]{1,31});`
 	ControlCharacters          = "[\u0000-\u0007\u000E-\u001B]"
-	OddCharactersPattern       = "(?im)^\\^l$|\u0080|\u0099|\u009C|\u009D|\u00AC|\u00E2|\u00A7|\u00C2|\u00A4|\u0153|\u20AC|\uFFFD"
 	LeadingWhitespacePattern   = `^\s`
 	MiddleWhitespacePattern    = "(?:\\s|\u00A0|\u2028|\u00B7)+"
 	TrailingWhitespacePattern  = `\s$`
 )
 
+// OddCharactersPattern matches odd characters and the mojibake of UTF-8 decoded as Windows-1252 or ISO-8859-1,
+// i.e. â€ (or â\u0080) and the next punctuation character, Â before a Latin-1 symbol, and Â which is not in a word
+// (before a no-break space). Only the submatch is removed when there is one. The letters â and œ are kept.
+const OddCharactersPattern = "(?im)^\\^l$" +
+	"|\u00E2[\u0080\u20AC][\u0080-\u00BF\u00FF\u0153\u0161\u017E\u0192\u02C6\u02DC\u2013\u2014\u2018-\u201E\u2020-\u2022\u2026\u2030\u2039\u203A]?" +
+	"|\u00E2[\u00A1-\u00BF]|(?:^|[^\\pL\\pN])(\u00E2)(?:\\s|$)" +
+	"|\u0080|\u0099|\u009C|\u009D|\u00AC|\u00A7|\u00A4|\u20AC|\uFFFD"
+
 var (
-	Logger         = log.NewLogger(log.INFO)
-	replacementREs = initVarietalWordSpellings()
+	Logger = log.NewLogger(log.INFO)
 
 	NoteTagPatternRE                  = regexp.MustCompile(NoteTagPattern)
 	WildcardMatchingPatternRE         = regexp.MustCompile(WildcardMatchingPattern)
@@ -77,32 +77,10 @@ var (
 	TrailingWhitespaceRE              = regexp.MustCompile(TrailingWhitespacePattern)
 	OddCharactersPatternRE            = regexp.MustCompile(OddCharactersPattern)
 	CopyrightRE                       = regexp.MustCompile(Copyright)
+	HTMLEntityRE                      = regexp.MustCompile(HTMLEntityPattern)
 	ControlCharactersRE               = regexp.MustCompile(ControlCharacters)
 )
 
-//go:embed replacement_words.json
-var replacementWordsBytes []byte
-
-func initVarietalWordSpellings() map[string]*regexp.Regexp {
-	REMap := make(map[string]*regexp.Regexp)
-
-	// Unmarshal replacement_words.json, compile REs, save in map (just once)
-	replacementWords := make(map[string]string)
-	if err := json.Unmarshal(replacementWordsBytes, &replacementWords); err != nil {
-		panic(err)
-	} else {
-		for replacement, pattern := range replacementWords {
-			re, err := regexp.Compile(pattern)
-			if err != nil {
-				panic(err)
-			}
-			REMap[replacement] = re
-		}
-	}
-
-	return REMap
-}
-
 // NormalizationData holds the input data and its normalized text
 type NormalizationData struct {
 	// original input text
@@ -113,6 +91,14 @@ type NormalizationData struct {
 	CaptureGroups  []*CaptureGroup
 	Hash           Digest
 	IsTemplate     bool
+	// CommentStyle, if set, is the comment style of the text (see CommentStyles.Select).
+	// Only its comment delimiters are removed instead of the generic comment indicators.
+	CommentStyle *CommentStyle
+	// Markup, if set, is the markup language of the text (see DetectMarkup), e.g. MarkupMarkdown.
+	// Its formatting is removed before the code comment indicators.
+	Markup string
+	// Trace, if set, records the effect of each normalization step
+	Trace          *Trace
 	initializeOnce sync.Once
 }
 
@@ -133,153 +119,64 @@ type Digest struct {
 	Sha512 string
 }
 
+// NewNormalizationData returns the normalization data of a text or a template.
+// A template written in a markup language (e.g. Markdown) has its markup removed like the texts in the same language.
 func NewNormalizationData(originalText string, isTemplate bool) *NormalizationData {
 	nd := NormalizationData{
 		OriginalText: originalText,
 		IsTemplate:   isTemplate,
 	}
+	if isTemplate {
+		nd.Markup = DetectMarkup("", originalText)
+	}
 	return &nd
 }
 
-// NormalizeText normalizes the input text
+// NormalizeText normalizes the input text with the default pipeline.
+// The libraryCacheVersion of the licenses package must be incremented with any change to this package which changes
+// the normalized text of an input, so that the normalized patterns and the scan results cached with the previous
+// version are not used. Changes to the embedded data files (see DataFingerprint) need no increment.
 func (n *NormalizationData) NormalizeText() error {
-	// verify that the original text is a string with a length of at least one.
-	if len(n.OriginalText) < 1 {
-		Logger.Error("Invalid text")
-		return fmt.Errorf("failed to normalize data: invalid input text with length %d", len(n.OriginalText))
-	}
-
-	// Check if the text contains control characters indicative of binary or non-text files.
-	// match against /[\u0000-\u0007\u000E-\u001B]/
-	if ControlCharactersRE.MatchString(n.OriginalText) {
-		return fmt.Errorf("failed to normalize data: invalid input text with control characters")
-	}
-
-	// remove note tags
-	n.removeNoteTags()
-
-	// replace the wild card matching pattern <<match=.+>> with the range of the permitted number of characters i.e. 1, 144
-	n.limitWildcardMatching()
-
-	// replace optional wild card matching pattern <<match=.*>> with the range of the permitted number of characters i.e. 0, 144
-	n.limitOptionalWildcardMatching()
-
-	// Capture replaceable text sections. (Guideline 2.1.3)
-	n.captureReplaceableTextSections()
-
-	// Replace the optional tags with <<omitable>> and <</omitable>>. (Guideline 2.1.4)
-	n.standardizeOmitableTags()
-
-	// remove odd characters, such as TM, replacement character ?, etc
-	// NOTE! Remove these before any use of regexp2 because rune chars throw off the index map
-	n.removeOddCharacters()
-
-	// Remove code comment indicators. (Guideline 6.1.1)
-	n.removeCodeCommentIndicators()
-
-	// SPDX matching guideline 5.1.2 (Hyphens, Dashes)
-	// Any hyphen, dash, en dash, em dash, or other variations should be considered equivalent
-	n.replaceDashLikeCharacters()
-
-	// SPDX matching guideline 5.1.3 (Quotes)
-	// Any variation of quotations (single, double, curly, etc.) should be considered equivalent
-	n.replaceQuoteLikeCharacters()
-
-	// SPDX matching guideline 13.1.1 - Standardize to http
-	// To avoid a license mismatch due to a difference in a hyperlink protocol (e.g. http vs. https).
-	// HTTP:// and HTTPS:// should be considered equivalent.
-	// Templates may or may not include markup for this guideline.
-	n.standardizeToHTTP()
-
-	// reconnect split words
-	n.reconnectSplitWords()
-
-	// remove horizontal rules
-	n.removeHorizontalRules()
-
-	// SPDX matching guideline 9.1.1 (Copyright Symbol)
-	// By having a rule regarding the use of “©”, “(c)”, or “copyright”,
-	// we avoid the possibility of a mismatch based on these variations.
-	// “©”, “(c)”, or “Copyright” should be considered equivalent and interchangeable.
-	// Templates do not include markup for this guideline so we replace all of these with `copyright`
-	n.replaceCopyrightSymbols()
-
-	// SPDX matching guideline 7.1.1 (Bullets and Numbering)
-	// * must be after replaceCopyrightSymbols() handle overlapping case (c)
-	n.replaceBulletsAndNumbering()
-
-	// Remove HTML tags
-	n.removeHTMLTags()
-
-	// TODO: Decode HTML entities.
-	// n.decodeHTMLEntities()
-
-	// Replace all whitespace with a single space. (Guideline 3.1.1)
-	// To avoid the possibility of a non-match due to different spacing of words, line breaks, or paragraphs.
-	// All whitespace should be treated as a single blank space.
-	n.replaceWhitespace()
-
-	// Replace varietal word spelling. (Guideline 8.1.1)
-	n.replaceVarietalWordSpellings()
-
-	// Add Hash Digest
-	// calculate MD5 for the normalized text
-	md5hash := md5.Sum([]byte(n.NormalizedText)) //nolint:gosec
-	n.Hash.Md5 = hex.EncodeToString(md5hash[:])
-
-	// calculate SHA256 for the normalized text
-	sha2hash := sha256.Sum256([]byte(n.NormalizedText))
-	n.Hash.Sha256 = hex.EncodeToString(sha2hash[:])
-
-	// calculate SHA512 for the normalized text
-	sha5hash := sha512.Sum512([]byte(n.NormalizedText))
-	n.Hash.Sha512 = hex.EncodeToString(sha5hash[:])
-
-	return nil
+	return defaultPipeline.Normalize(n)
 }
 
 // initializeIndexMap initializes the index map based on the normalized text
 func (n *NormalizationData) initialize() {
 	n.initializeOnce.Do(func() {
-		// Convert the input text to all lower case. (Guideline 4.1.1)
-		// Note: Regex patterns also assume ToLower() was already done to avoid needing case-insensitive match.
-		n.NormalizedText = strings.ToLower(n.OriginalText)
-
-		// generate an index map, to map the normalized text indices back to the respective index in the original text
-		// Note: ToLower() must be done before creating IndexMap because some chars change in length.
-		l := len(n.NormalizedText)
-		n.IndexMap = make([]int, l)
-		for i := 0; i < l; i++ {
-			n.IndexMap[i] = i
-		}
+		// Apply Unicode NFKC normalization, remove zero-width characters and convert the input text to all lower case.
+		// (Guideline 4.1.1)
+		// Note: Regex patterns also assume the lower case was already done to avoid needing case-insensitive match.
+		// The index map maps the normalized text indices back to the respective index in the original text.
+		// Some chars change in length, so the map is built as each char is folded.
+		n.NormalizedText, n.IndexMap = foldText(n.OriginalText)
 	})
 }
 
 func (n *NormalizationData) removeNoteTags() {
-	n.regexpReplacePatternAndUpdateIndexMap(NoteTagPatternRE, " ")
+	n.RegexpReplacePatternAndUpdateIndexMap(NoteTagPatternRE, " ")
 }
 
 // limitWildcardMatching replaces the wild card matching pattern <<match=.+>> with the permitted number of characters i.e. 1, 144
 func (n *NormalizationData) limitWildcardMatching() {
-	n.regexpReplacePatternAndUpdateIndexMap(WildcardMatchingPatternRE, `<<.{1,144}>>`)
+	n.RegexpReplacePatternAndUpdateIndexMap(WildcardMatchingPatternRE, `<<.{1,144}>>`)
 }
 
 // limitOptionalWildcardMatching replaces the wild card matching pattern <<match=.*>> with the permitted number of characters i.e. 0, 144
 func (n *NormalizationData) limitOptionalWildcardMatching() {
-	n.regexpReplacePatternAndUpdateIndexMap(OptionalWildcardMatchingPatternRE, `<<.{0,144}>>`)
+	n.RegexpReplacePatternAndUpdateIndexMap(OptionalWildcardMatchingPatternRE, `<<.{0,144}>>`)
 }
 
-// regexpRemovePatternAndUpdateIndexMap removes all occurrences matching the regex from in the normalized text
+// RegexpRemovePatternAndUpdateIndexMap removes all occurrences matching the regex from in the normalized text
 // the index map is updated based on the updated normalized text
 // Array of strings returned is the set of unique strings from submatches, if any
-func (n *NormalizationData) regexpRemovePatternAndUpdateIndexMap(re *regexp.Regexp) []string {
-	return n.regexpReplacePatternAndUpdateIndexMap(re, "")
+func (n *NormalizationData) RegexpRemovePatternAndUpdateIndexMap(re *regexp.Regexp) []string {
+	return n.RegexpReplacePatternAndUpdateIndexMap(re, "")
 }
 
-// regexpReplacePatternAndUpdateIndexMap replaces all occurrences matching the regexp2 pattern from in the normalized text
+// RegexpReplacePatternAndUpdateIndexMap replaces all occurrences matching the regexp2 pattern from in the normalized text
 // the normalized text has this pattern match replaced with the replacement string
 // the index map is updated based on the updated normalized text
-func (n *NormalizationData) regexpReplacePatternAndUpdateIndexMap(re *regexp.Regexp, replacement string) []string {
+func (n *NormalizationData) RegexpReplacePatternAndUpdateIndexMap(re *regexp.Regexp, replacement string) []string {
 	n.initialize() // initialize normalized text and index map if not set already
 	allSubmatchIndex := re.FindAllStringSubmatchIndex(n.NormalizedText, len(n.NormalizedText))
 	return n.replaceMatchesWithStringAndUpdateIndexMap(allSubmatchIndex, replacement)
@@ -342,27 +239,32 @@ func (n *NormalizationData) captureReplaceableTextSections() {
 		n.CaptureGroups = append(n.CaptureGroups, c)
 	}
 
-	n.replaceMatchesWithStringsAndUpdateIndexMap(allSubmatchIndex, replacements)
+	n.ReplaceMatchesWithStringsAndUpdateIndexMap(allSubmatchIndex, replacements)
 }
 
 func (n *NormalizationData) standardizeOmitableTags() {
-	n.regexpReplacePatternAndUpdateIndexMap(BeginOptionalLinePatternRE, OmitableLine) // Allows other $(m)^ matches
-	n.regexpReplacePatternAndUpdateIndexMap(BeginOptionalPatternRE, Omitable)
-	n.regexpReplacePatternAndUpdateIndexMap(EndOptionalPatternRE, ReplaceEndPattern)
+	n.RegexpReplacePatternAndUpdateIndexMap(BeginOptionalLinePatternRE, OmitableLine) // Allows other $(m)^ matches
+	n.RegexpReplacePatternAndUpdateIndexMap(BeginOptionalPatternRE, Omitable)
+	n.RegexpReplacePatternAndUpdateIndexMap(EndOptionalPatternRE, ReplaceEndPattern)
 }
 
 func (n *NormalizationData) removeCodeCommentIndicators() {
+	if n.CommentStyle != nil && !n.IsTemplate {
+		n.removeCommentDelimiters(n.CommentStyle)
+		return
+	}
+
 	// Remove comment block indicators
-	n.regexpReplacePatternAndUpdateIndexMap(CommentBlockOutsideRE, " ")
-	n.regexpReplacePatternAndUpdateIndexMap(CommentBlockInsideRE, " ")
+	n.RegexpReplacePatternAndUpdateIndexMap(CommentBlockOutsideRE, " ")
+	n.RegexpReplacePatternAndUpdateIndexMap(CommentBlockInsideRE, " ")
 
 	// Remove HTML-style comments
 	// the HTML comments are replaced first before matching single line comment to avoid accidental partial matching of
 	// the HTML comment tags with the CommentLinePattern expression i.e. -- | >
-	n.regexpReplacePatternAndUpdateIndexMap(HtmlStyleCommentRE, " ")
+	n.RegexpReplacePatternAndUpdateIndexMap(HtmlStyleCommentRE, " ")
 
 	// Remove comment line indicators
-	n.regexpReplacePatternAndUpdateIndexMap(CommentLineRE, " ")
+	n.RegexpReplacePatternAndUpdateIndexMap(CommentLineRE, " ")
 }
 
 func (n *NormalizationData) removeHTMLTags() {
@@ -415,16 +317,47 @@ func (n *NormalizationData) removeHTMLTags() {
 	n.replaceMatchesWithStringAndUpdateIndexMap(allSubmatchIndex, replacement)
 }
 
+// decodeHTMLEntities replaces named and numeric HTML entities with the characters they represent.
+// The steps before this one have already standardized dashes, quotes and copyright symbols,
+// so the decoded characters are standardized the same way (e.g. &copy; and &#169; become `copyright`).
+// Each entity is replaced as a whole so the index map still points to the entity in the original text.
+func (n *NormalizationData) decodeHTMLEntities() {
+	n.initialize() // initialize normalized text and index map if not set already
+	allSubmatchIndex := HTMLEntityRE.FindAllStringSubmatchIndex(n.NormalizedText, len(n.NormalizedText))
+
+	var matches [][]int
+	var replacements []string
+	for _, match := range allSubmatchIndex {
+		entity := n.NormalizedText[match[0]:match[1]]
+		decoded := html.UnescapeString(entity)
+		if decoded == entity {
+			continue // not a known entity, so leave the text as-is
+		}
+		// Templates use << and >> for markup, so keep &lt; and &gt; in templates
+		if n.IsTemplate && strings.ContainsAny(decoded, "<>") {
+			continue
+		}
+		decoded = strings.ToLower(decoded)
+		decoded = DashLikeRE.ReplaceAllString(decoded, "-")
+		decoded = QuoteLikeRE.ReplaceAllString(decoded, "'")
+		decoded = CopyrightRE.ReplaceAllString(decoded, "copyright")
+		matches = append(matches, match)
+		replacements = append(replacements, decoded)
+	}
+
+	n.ReplaceMatchesWithStringsAndUpdateIndexMap(matches, replacements)
+}
+
 func (n *NormalizationData) replaceDashLikeCharacters() {
-	n.regexpReplacePatternAndUpdateIndexMap(DashLikeRE, "-")
+	n.RegexpReplacePatternAndUpdateIndexMap(DashLikeRE, "-")
 }
 
 func (n *NormalizationData) replaceQuoteLikeCharacters() {
-	n.regexpReplacePatternAndUpdateIndexMap(QuoteLikeRE, "'")
+	n.RegexpReplacePatternAndUpdateIndexMap(QuoteLikeRE, "'")
 }
 
 func (n *NormalizationData) standardizeToHTTP() {
-	n.regexpReplacePatternAndUpdateIndexMap(HTTPPatternRE, "http")
+	n.RegexpReplacePatternAndUpdateIndexMap(HTTPPatternRE, "http")
 }
 
 // replaceBulletsAndNumbering removes or replaces bullets and outline numbering to avoid common mismatches
@@ -436,45 +369,41 @@ func (n *NormalizationData) replaceBulletsAndNumbering() {
 	// * In templates use a wildcard matcher to make bullets/numbers optional (matching replaced or not)
 	if n.IsTemplate {
 		replacement := "<<.{0,20}?>>"
-		n.regexpReplacePatternAndUpdateIndexMap(BulletsPatternRE, replacement)
-		n.regexpReplacePatternAndUpdateIndexMap(NumberingPatternRE, replacement)
+		n.RegexpReplacePatternAndUpdateIndexMap(BulletsPatternRE, replacement)
+		n.RegexpReplacePatternAndUpdateIndexMap(NumberingPatternRE, replacement)
 	} else {
-		n.regexpRemovePatternAndUpdateIndexMap(BulletsPatternRE)
+		n.RegexpRemovePatternAndUpdateIndexMap(BulletsPatternRE)
 	}
 }
 
 func (n *NormalizationData) reconnectSplitWords() {
-	n.regexpRemovePatternAndUpdateIndexMap(SplitWordsRE)
+	n.RegexpRemovePatternAndUpdateIndexMap(SplitWordsRE)
 }
 
 func (n *NormalizationData) removeHorizontalRules() {
-	n.regexpReplacePatternAndUpdateIndexMap(HorizontalRulePatternRE, " ")
-}
-
-// replaceVarietalWordSpellings will read replacement words JSON file and replace matches
-// to create a consistent representation of words with alternate spellings.
-// The replacement may be a word or regexp pattern.
-// The file is only read once.
-// The patterns are only compiled once.
-// The compiled regexp are kept in a map for reuse.
-func (n *NormalizationData) replaceVarietalWordSpellings() {
-	for replacement, re := range replacementREs {
-		n.regexpReplacePatternAndUpdateIndexMap(re, replacement)
-	}
+	n.RegexpReplacePatternAndUpdateIndexMap(HorizontalRulePatternRE, " ")
 }
 
 func (n *NormalizationData) replaceCopyrightSymbols() {
-	n.regexpReplacePatternAndUpdateIndexMap(CopyrightRE, "copyright")
+	n.RegexpReplacePatternAndUpdateIndexMap(CopyrightRE, "copyright")
 }
 
 func (n *NormalizationData) removeOddCharacters() {
-	n.regexpReplacePatternAndUpdateIndexMap(OddCharactersPatternRE, " ")
+	n.initialize()
+	var odd [][]int
+	for _, match := range OddCharactersPatternRE.FindAllStringSubmatchIndex(n.NormalizedText, -1) {
+		if match[2] >= 0 {
+			match = match[2:4] // the Â, without the characters around it
+		}
+		odd = append(odd, match[:2])
+	}
+	n.replaceMatchesWithStringAndUpdateIndexMap(odd, " ")
 }
 
 func (n *NormalizationData) replaceWhitespace() {
-	n.regexpReplacePatternAndUpdateIndexMap(MiddleWhitespaceRE, " ")
-	n.regexpRemovePatternAndUpdateIndexMap(LeadingWhitespaceRE)
-	n.regexpRemovePatternAndUpdateIndexMap(TrailingWhitespaceRE)
+	n.RegexpReplacePatternAndUpdateIndexMap(MiddleWhitespaceRE, " ")
+	n.RegexpRemovePatternAndUpdateIndexMap(LeadingWhitespaceRE)
+	n.RegexpRemovePatternAndUpdateIndexMap(TrailingWhitespaceRE)
 }
 
 func (n *NormalizationData) replaceMatchesWithStringAndUpdateIndexMap(allSubmatchIndex [][]int, replacement string) []string {
@@ -489,6 +418,7 @@ func (n *NormalizationData) replaceMatchesWithStringAndUpdateIndexMap(allSubmatc
 	}
 
 	var submatches []string
+	seen := make(map[string]bool)
 	// extract submatch strings, if any, to return to the caller
 	for _, match := range allSubmatchIndex {
 		for i := 3; i < len(match); i += 2 {
@@ -496,15 +426,16 @@ func (n *NormalizationData) replaceMatchesWithStringAndUpdateIndexMap(allSubmatc
 			to := match[i]
 			if from >= 0 && to > from { // skipping -1 or invalid range
 				submatch := substr(n.NormalizedText, from, to)
-				if !slices.Contains(submatches, submatch) {
+				if !seen[submatch] {
 					// only keep the unique strings
+					seen[submatch] = true
 					submatches = append(submatches, submatch)
 				}
 			}
 		}
 	}
 
-	n.replaceMatchesWithStringsAndUpdateIndexMap(allSubmatchIndex, replacements)
+	n.ReplaceMatchesWithStringsAndUpdateIndexMap(allSubmatchIndex, replacements)
 	return submatches
 }
 
@@ -524,17 +455,29 @@ func subset[T any](thing []T, from int, to int) []T {
 	return thing[from:to]
 }
 
-// replaceMatchesWithStringAndUpdateIndexMap iterates over matches to:
+// ReplaceMatchesWithStringsAndUpdateIndexMap iterates over matches to:
 // * remove or replace the matched text
 // * build an updated index map
-// * retrieve and return unique submatch strings, if applicable
-func (n *NormalizationData) replaceMatchesWithStringsAndUpdateIndexMap(allSubmatchIndex [][]int, replacements []string) {
+//
+// The new text and index map are built in one pass, in time linear in the length of the text. The index map always
+// maps to the original text, so the mappings of all the passes are composed as each pass is applied.
+func (n *NormalizationData) ReplaceMatchesWithStringsAndUpdateIndexMap(allSubmatchIndex [][]int, replacements []string) {
+	n.initialize() // initialize normalized text and index map if not set already
 	if allSubmatchIndex == nil {
 		return
 	}
 
-	var newText string
-	var newIndex []int
+	// size the new text and index map up front
+	size := len(n.NormalizedText)
+	for i, match := range allSubmatchIndex {
+		size += len(replacements[i]) - (match[1] - match[0])
+	}
+	if size < 0 {
+		size = 0
+	}
+	var newText strings.Builder
+	newText.Grow(size)
+	newIndex := make([]int, 0, size)
 
 	prev := 0
 	for i, match := range allSubmatchIndex {
@@ -542,10 +485,13 @@ func (n *NormalizationData) replaceMatchesWithStringsAndUpdateIndexMap(allSubmat
 		firstIndex := match[0]
 		lastIndex := match[1]
 		replacement := replacements[i]
+		if n.Trace != nil {
+			n.Trace.edit(n, firstIndex, lastIndex, replacement)
+		}
 
 		// copy the text and index map before (and in between) matches
 		if prev < len(n.IndexMap) && firstIndex > prev {
-			newText += substr(n.NormalizedText, prev, firstIndex)
+			newText.WriteString(substr(n.NormalizedText, prev, firstIndex))
 			newIndex = append(newIndex, subset(n.IndexMap, prev, firstIndex)...)
 		}
 
@@ -555,20 +501,19 @@ func (n *NormalizationData) replaceMatchesWithStringsAndUpdateIndexMap(allSubmat
 			// * The first element should be the first index in the replaced section.
 			// * The last element should be the last index in the replaced section. (Unless there is only a single char)
 			// * Middle elements should be -1, for 'replaced'. A match starting/ending on these indices is invalid.
-			replacementIndex := make([]int, replacementLen)
-			for i := 0; i < cap(replacementIndex); i++ {
-				replacementIndex[i] = -1
+			start := len(newIndex)
+			for j := 0; j < replacementLen; j++ {
+				newIndex = append(newIndex, -1)
 			}
 			if firstIndex < len(n.IndexMap) {
-				replacementIndex[0] = n.IndexMap[firstIndex]
+				newIndex[start] = n.IndexMap[firstIndex]
 			}
 			if replacementLen > 1 && lastIndex-1 < len(n.IndexMap) {
-				replacementIndex[replacementLen-1] = n.IndexMap[lastIndex-1]
+				newIndex[start+replacementLen-1] = n.IndexMap[lastIndex-1]
 			}
 
-			// Append the replacement text and indexes
-			newText += replacement
-			newIndex = append(newIndex, replacementIndex...)
+			// Append the replacement text
+			newText.WriteString(replacement)
 		}
 
 		prev = lastIndex
@@ -576,11 +521,11 @@ func (n *NormalizationData) replaceMatchesWithStringsAndUpdateIndexMap(allSubmat
 
 	// Append the remaining text and indexes, if there are more after the last match
 	if prev < len(n.IndexMap) {
-		newText += n.NormalizedText[prev:]
+		newText.WriteString(n.NormalizedText[prev:])
 		newIndex = append(newIndex, n.IndexMap[prev:]...)
 	}
 
 	// Set the new text and index map
-	n.NormalizedText = newText
+	n.NormalizedText = newText.String()
 	n.IndexMap = newIndex
 }
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package normalizer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchmarkNotice is a typical third-party notice entry, repeated to build large concatenated NOTICE files
+const benchmarkNotice = `---------------------------------------------------------------------------
+Package: example-package (1.2.3)
+Copyright (c) 2015-2022 The Example Authors
+
+   * Licensed under the Apache License, Version 2.0 (the "License");
+   * you may not use this file except in compliance with the License.
+   * You may obtain a copy of the License at https://www.apache.org/licenses/LICENSE-2.0
+
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+   1. Redistributions of source code must retain the above copyright notice.
+   2. Redistributions in binary form must reproduce the above copyright notice.
+<p>THIS SOFTWARE IS PROVIDED “AS IS” — WITHOUT WARRANTY OF ANY KIND.</p>
+
+`
+
+func BenchmarkNormalizeText(b *testing.B) {
+	for _, size := range []int{10_000, 100_000, 1_000_000, 10_000_000} {
+		text := strings.Repeat(benchmarkNotice, size/len(benchmarkNotice)+1)[:size]
+		b.Run(fmt.Sprintf("%vKB", size/1000), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				n := NormalizationData{OriginalText: text}
+				if err := n.NormalizeText(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
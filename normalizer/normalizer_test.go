@@ -630,6 +630,68 @@ func TestNormalizationData_NormalizeText_replaceCopyrightSymbols(t *testing.T) {
 	}
 }
 
+func TestNormalizationData_NormalizeText_decodeHTMLEntities(t *testing.T) {
+	tcs := []struct {
+		name string
+		n    *NormalizationData
+		e    *NormalizationData
+	}{
+		{
+			name: "named and numeric entities",
+			n: &NormalizationData{
+				OriginalText: "&quot;AS IS&quot; &amp; &#x2014; &eacute;",
+			},
+			e: &NormalizationData{
+				NormalizedText: "'as is' & - é",
+				IndexMap:       []int{0, 6, 7, 8, 9, 10, 11, 17, 18, 23, 24, 32, 33, 40},
+			},
+		},
+		{
+			name: "copyright entities",
+			n: &NormalizationData{
+				OriginalText: "&copy; &#169; 2023",
+			},
+			e: &NormalizationData{
+				NormalizedText: "copyright copyright 2023",
+				IndexMap:       []int{0, -1, -1, -1, -1, -1, -1, -1, 5, 6, 7, -1, -1, -1, -1, -1, -1, -1, 12, 13, 14, 15, 16, 17},
+			},
+		},
+		{
+			name: "unknown entities and ampersands are unchanged",
+			n: &NormalizationData{
+				OriginalText: "AT&T; R&D &nosuchentity;",
+			},
+			e: &NormalizationData{
+				NormalizedText: "at&t; r&d &nosuchentity;",
+				IndexMap:       []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23},
+			},
+		},
+		{
+			name: "template markup is not decoded",
+			n: &NormalizationData{
+				OriginalText: "a &lt;&lt; b &amp;",
+				IsTemplate:   true,
+			},
+			e: &NormalizationData{
+				NormalizedText: "a &lt;&lt; b &",
+				IndexMap:       []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.n.decodeHTMLEntities()
+			if d := cmp.Diff(tc.e.NormalizedText, tc.n.NormalizedText); d != "" {
+				t.Errorf("Didn't get expected Normalized text: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+			if d := cmp.Diff(tc.e.IndexMap, tc.n.IndexMap); d != "" {
+				t.Errorf("Didn't get expected IndexMap: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+		})
+	}
+}
+
 func TestNormalizationData_NormalizeText_removeOddCharacters(t *testing.T) {
 	tcs := []struct {
 		name string
@@ -642,6 +704,22 @@ func TestNormalizationData_NormalizeText_removeOddCharacters(t *testing.T) {
 		e: &NormalizationData{
 			NormalizedText: fmt.Sprintf("trademark    not sign  "),
 		},
+	}, {
+		name: "letters of the mojibake",
+		n: &NormalizationData{
+			OriginalText: "Dégâts, Âge, Œuvre et cœur",
+		},
+		e: &NormalizationData{
+			NormalizedText: "dégâts, âge, œuvre et cœur",
+		},
+	}, {
+		name: "mojibake",
+		n: &NormalizationData{
+			OriginalText: "It\u00E2\u20AC\u2122s \u00E2\u20AC\u0153free\u00E2\u20AC\u009D, \u00C2\u00A9 2020,\u00C2\u00A0ACME and it\u00E2\u0080\u0099s",
+		},
+		e: &NormalizationData{
+			NormalizedText: "it s  free ,   2020,  acme and it s",
+		},
 	}}
 
 	for _, tc := range tcs {
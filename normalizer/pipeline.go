@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+)
+
+// Names of the steps in the default pipeline
+const (
+	StepRemoveNoteTags                 = "removeNoteTags"
+	StepLimitWildcardMatching          = "limitWildcardMatching"
+	StepLimitOptionalWildcardMatching  = "limitOptionalWildcardMatching"
+	StepCaptureReplaceableTextSections = "captureReplaceableTextSections"
+	StepStandardizeOmitableTags        = "standardizeOmitableTags"
+	StepRemoveOddCharacters            = "removeOddCharacters"
+	StepRemoveMarkup                   = "removeMarkup"
+	StepRemoveCodeCommentIndicators    = "removeCodeCommentIndicators"
+	StepReplaceDashLikeCharacters      = "replaceDashLikeCharacters"
+	StepReplaceQuoteLikeCharacters     = "replaceQuoteLikeCharacters"
+	StepStandardizeToHTTP              = "standardizeToHTTP"
+	StepReconnectSplitWords            = "reconnectSplitWords"
+	StepRemoveHorizontalRules          = "removeHorizontalRules"
+	StepReplaceCopyrightSymbols        = "replaceCopyrightSymbols"
+	StepReplaceBulletsAndNumbering     = "replaceBulletsAndNumbering"
+	StepRemoveHTMLTags                 = "removeHTMLTags"
+	StepDecodeHTMLEntities             = "decodeHTMLEntities"
+	StepReplaceWhitespace              = "replaceWhitespace"
+	StepReplaceVarietalWordSpellings   = "replaceVarietalWordSpellings"
+)
+
+// Step is a named normalization step.
+// Apply updates the NormalizedText of the normalization data. To keep the IndexMap pointing to the original text,
+// use RegexpReplacePatternAndUpdateIndexMap, RegexpRemovePatternAndUpdateIndexMap
+// or ReplaceMatchesWithStringsAndUpdateIndexMap to change the text.
+type Step struct {
+	Name  string
+	Apply func(n *NormalizationData) error
+}
+
+// Pipeline is the sequence of steps that normalize a text.
+// The methods which change a pipeline return a new pipeline, so the default pipeline is never changed.
+type Pipeline []Step
+
+var defaultPipeline = Pipeline{
+	step(StepRemoveNoteTags, (*NormalizationData).removeNoteTags),
+
+	// replace the wild card matching pattern <<match=.+>> with the range of the permitted number of characters i.e. 1, 144
+	step(StepLimitWildcardMatching, (*NormalizationData).limitWildcardMatching),
+
+	// replace optional wild card matching pattern <<match=.*>> with the range of the permitted number of characters i.e. 0, 144
+	step(StepLimitOptionalWildcardMatching, (*NormalizationData).limitOptionalWildcardMatching),
+
+	// Capture replaceable text sections. (Guideline 2.1.3)
+	step(StepCaptureReplaceableTextSections, (*NormalizationData).captureReplaceableTextSections),
+
+	// Replace the optional tags with <<omitable>> and <</omitable>>. (Guideline 2.1.4)
+	step(StepStandardizeOmitableTags, (*NormalizationData).standardizeOmitableTags),
+
+	// remove odd characters, such as TM, replacement character ?, etc
+	// NOTE! Remove these before any use of regexp2 because rune chars throw off the index map
+	step(StepRemoveOddCharacters, (*NormalizationData).removeOddCharacters),
+
+	// Remove Markdown or reStructuredText formatting (e.g. headings, emphasis and link syntax)
+	// * must be before removeCodeCommentIndicators() so that leading ** and # are removed as markup
+	step(StepRemoveMarkup, (*NormalizationData).removeMarkup),
+
+	// Remove code comment indicators. (Guideline 6.1.1)
+	step(StepRemoveCodeCommentIndicators, (*NormalizationData).removeCodeCommentIndicators),
+
+	// SPDX matching guideline 5.1.2 (Hyphens, Dashes)
+	// Any hyphen, dash, en dash, em dash, or other variations should be considered equivalent
+	step(StepReplaceDashLikeCharacters, (*NormalizationData).replaceDashLikeCharacters),
+
+	// SPDX matching guideline 5.1.3 (Quotes)
+	// Any variation of quotations (single, double, curly, etc.) should be considered equivalent
+	step(StepReplaceQuoteLikeCharacters, (*NormalizationData).replaceQuoteLikeCharacters),
+
+	// SPDX matching guideline 13.1.1 - Standardize to http
+	// To avoid a license mismatch due to a difference in a hyperlink protocol (e.g. http vs. https).
+	// HTTP:// and HTTPS:// should be considered equivalent.
+	// Templates may or may not include markup for this guideline.
+	step(StepStandardizeToHTTP, (*NormalizationData).standardizeToHTTP),
+
+	step(StepReconnectSplitWords, (*NormalizationData).reconnectSplitWords),
+
+	step(StepRemoveHorizontalRules, (*NormalizationData).removeHorizontalRules),
+
+	// SPDX matching guideline 9.1.1 (Copyright Symbol)
+	// By having a rule regarding the use of “©”, “(c)”, or “copyright”,
+	// we avoid the possibility of a mismatch based on these variations.
+	// “©”, “(c)”, or “Copyright” should be considered equivalent and interchangeable.
+	// Templates do not include markup for this guideline so we replace all of these with `copyright`
+	step(StepReplaceCopyrightSymbols, (*NormalizationData).replaceCopyrightSymbols),
+
+	// SPDX matching guideline 7.1.1 (Bullets and Numbering)
+	// * must be after replaceCopyrightSymbols() handle overlapping case (c)
+	step(StepReplaceBulletsAndNumbering, (*NormalizationData).replaceBulletsAndNumbering),
+
+	step(StepRemoveHTMLTags, (*NormalizationData).removeHTMLTags),
+
+	// Decode HTML entities (e.g. &quot; &amp; &#169;)
+	// * must be after removeHTMLTags() so that decoded &lt; and &gt; are not removed as tags
+	step(StepDecodeHTMLEntities, (*NormalizationData).decodeHTMLEntities),
+
+	// Replace all whitespace with a single space. (Guideline 3.1.1)
+	// To avoid the possibility of a non-match due to different spacing of words, line breaks, or paragraphs.
+	// All whitespace should be treated as a single blank space.
+	step(StepReplaceWhitespace, (*NormalizationData).replaceWhitespace),
+
+	// Replace varietal word spelling. (Guideline 8.1.1)
+	{Name: StepReplaceVarietalWordSpellings, Apply: (*NormalizationData).replaceVarietalWordSpellings},
+}
+
+func step(name string, apply func(n *NormalizationData)) Step {
+	return Step{Name: name, Apply: func(n *NormalizationData) error {
+		apply(n)
+		return nil
+	}}
+}
+
+// DefaultPipeline returns a copy of the steps used by NormalizeText
+func DefaultPipeline() Pipeline {
+	return append(Pipeline{}, defaultPipeline...)
+}
+
+// DataFingerprint returns a hash of the embedded data files used by the normalization steps
+// (the replacement words and the comment styles), which changes whenever they change
+func DataFingerprint() string {
+	h := sha256.New()
+	for _, b := range [][]byte{replacementWordsBytes, commentStylesBytes} {
+		_, _ = fmt.Fprintf(h, "%v\n", len(b))
+		_, _ = h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Names returns the names of the steps in order
+func (p Pipeline) Names() []string {
+	names := make([]string, 0, len(p))
+	for _, s := range p {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// Without returns the pipeline without the named steps
+func (p Pipeline) Without(names ...string) Pipeline {
+	without := make(Pipeline, 0, len(p))
+	for _, s := range p {
+		skip := false
+		for _, name := range names {
+			if s.Name == name {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			without = append(without, s)
+		}
+	}
+	return without
+}
+
+// Replace returns the pipeline with the step of the same name replaced by the step
+func (p Pipeline) Replace(step Step) (Pipeline, error) {
+	i := p.index(step.Name)
+	if i < 0 {
+		return nil, fmt.Errorf("normalization step %q not found", step.Name)
+	}
+	replaced := append(Pipeline{}, p...)
+	replaced[i] = step
+	return replaced, nil
+}
+
+// InsertBefore returns the pipeline with the steps inserted before the named step
+func (p Pipeline) InsertBefore(name string, steps ...Step) (Pipeline, error) {
+	i := p.index(name)
+	if i < 0 {
+		return nil, fmt.Errorf("normalization step %q not found", name)
+	}
+	return p.insert(i, steps), nil
+}
+
+// InsertAfter returns the pipeline with the steps inserted after the named step
+func (p Pipeline) InsertAfter(name string, steps ...Step) (Pipeline, error) {
+	i := p.index(name)
+	if i < 0 {
+		return nil, fmt.Errorf("normalization step %q not found", name)
+	}
+	return p.insert(i+1, steps), nil
+}
+
+func (p Pipeline) index(name string) int {
+	for i, s := range p {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p Pipeline) insert(i int, steps []Step) Pipeline {
+	inserted := make(Pipeline, 0, len(p)+len(steps))
+	inserted = append(inserted, p[:i]...)
+	inserted = append(inserted, steps...)
+	return append(inserted, p[i:]...)
+}
+
+// Normalize normalizes the original text with the steps of the pipeline and calculates the hashes of the normalized text
+func (p Pipeline) Normalize(n *NormalizationData) error {
+	// verify that the original text is a string with a length of at least one.
+	if len(n.OriginalText) < 1 {
+		Logger.Error("Invalid text")
+		return fmt.Errorf("failed to normalize data: invalid input text with length %d", len(n.OriginalText))
+	}
+
+	// Check if the text contains control characters indicative of binary or non-text files.
+	// match against /[\u0000-\u0007\u000E-\u001B]/
+	if ControlCharactersRE.MatchString(n.OriginalText) {
+		return fmt.Errorf("failed to normalize data: invalid input text with control characters")
+	}
+
+	n.initialize() // initialize normalized text and index map if not set already
+	if n.Trace != nil {
+		n.Trace.step(StepInitialize, n)
+	}
+	for _, s := range p {
+		if err := s.Apply(n); err != nil {
+			return fmt.Errorf("failed to normalize data: step %v: %w", s.Name, err)
+		}
+		if n.Trace != nil {
+			n.Trace.step(s.Name, n)
+		}
+	}
+
+	// Add Hash Digest
+	// calculate MD5 for the normalized text
+	md5hash := md5.Sum([]byte(n.NormalizedText)) //nolint:gosec
+	n.Hash.Md5 = hex.EncodeToString(md5hash[:])
+
+	// calculate SHA256 for the normalized text
+	sha2hash := sha256.Sum256([]byte(n.NormalizedText))
+	n.Hash.Sha256 = hex.EncodeToString(sha2hash[:])
+
+	// calculate SHA512 for the normalized text
+	sha5hash := sha512.Sum512([]byte(n.NormalizedText))
+	n.Hash.Sha512 = hex.EncodeToString(sha5hash[:])
+
+	return nil
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package normalizer
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPipeline_Normalize(t *testing.T) {
+	t.Parallel()
+	bannerRE := regexp.MustCompile(`\*\*\* acme corp internal \*\*\*`)
+	removeBanner := Step{
+		Name: "removeBanner",
+		Apply: func(n *NormalizationData) error {
+			n.RegexpRemovePatternAndUpdateIndexMap(bannerRE)
+			return nil
+		},
+	}
+	withBanner, err := DefaultPipeline().InsertBefore(StepRemoveCodeCommentIndicators, removeBanner)
+	if err != nil {
+		t.Fatalf("InsertBefore() error = %v", err)
+	}
+
+	tcs := []struct {
+		name             string
+		pipeline         Pipeline
+		text             string
+		expectedText     string
+		expectedIndexMap []int
+	}{
+		{
+			name:             "default",
+			pipeline:         DefaultPipeline(),
+			text:             "A “B”",
+			expectedText:     "a 'b'",
+			expectedIndexMap: []int{0, 1, 2, 5, 6},
+		},
+		{
+			name:             "without a step",
+			pipeline:         DefaultPipeline().Without(StepReplaceQuoteLikeCharacters),
+			text:             "A “B”",
+			expectedText:     "a “b”",
+			expectedIndexMap: []int{0, 1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			name:             "inserted step",
+			pipeline:         withBanner,
+			text:             "*** ACME Corp Internal ***\nMIT",
+			expectedText:     "mit",
+			expectedIndexMap: []int{27, 28, 29},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			n := NormalizationData{OriginalText: tc.text}
+			if err := tc.pipeline.Normalize(&n); err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if d := cmp.Diff(tc.expectedText, n.NormalizedText); d != "" {
+				t.Errorf("Didn't get expected Normalized text: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+			if d := cmp.Diff(tc.expectedIndexMap, n.IndexMap); d != "" {
+				t.Errorf("Didn't get expected IndexMap: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+		})
+	}
+}
+
+func TestPipeline_Steps(t *testing.T) {
+	t.Parallel()
+	if d := cmp.Diff(len(defaultPipeline), len(DefaultPipeline().Without("noSuchStep"))); d != "" {
+		t.Errorf("Without() an unknown step changed the pipeline: (-want, +got): %s", d)
+	}
+	names := DefaultPipeline().Without(StepRemoveNoteTags, StepReplaceVarietalWordSpellings).Names()
+	if names[0] != StepLimitWildcardMatching || names[len(names)-1] != StepReplaceWhitespace {
+		t.Errorf("Without() didn't remove the first and last steps: %v", names)
+	}
+	if _, err := DefaultPipeline().InsertAfter("noSuchStep", Step{Name: "x"}); err == nil {
+		t.Errorf("InsertAfter() expected an error for an unknown step")
+	}
+	custom, err := DefaultPipeline().InsertAfter(StepReplaceVarietalWordSpellings, Step{Name: "last"})
+	if err != nil {
+		t.Fatalf("InsertAfter() error = %v", err)
+	}
+	if custom[len(custom)-1].Name != "last" || len(defaultPipeline) != len(custom)-1 {
+		t.Errorf("InsertAfter() didn't append a step to a copy: %v", custom.Names())
+	}
+}
+
+// TestDataFingerprint is not parallel, since it changes the embedded comment styles
+func TestDataFingerprint(t *testing.T) {
+	original := DataFingerprint()
+	if d := cmp.Diff(original, DataFingerprint()); d != "" {
+		t.Errorf("Didn't get the same fingerprint: (-want, +got): %v", d)
+	}
+
+	embedded := commentStylesBytes
+	defer func() { commentStylesBytes = embedded }()
+	commentStylesBytes = append(append([]byte{}, embedded...), '\n')
+	if DataFingerprint() == original {
+		t.Error("expected a different fingerprint for changed comment styles")
+	}
+}
+
+func TestPipeline_Trace(t *testing.T) {
+	t.Parallel()
+	n := NormalizationData{OriginalText: "// (c) Licence", Trace: &Trace{}}
+	if err := n.NormalizeText(); err != nil {
+		t.Fatalf("NormalizeText() error = %v", err)
+	}
+	if d := cmp.Diff(append([]string{StepInitialize}, defaultPipeline.Names()...), stepNames(n.Trace.Steps)); d != "" {
+		t.Errorf("Didn't get expected steps: (-want, +got): %s", d)
+	}
+
+	edits := make(map[string][]Edit)
+	for _, s := range n.Trace.Steps {
+		if len(s.Edits) > 0 {
+			edits[s.Name] = s.Edits
+		}
+	}
+	expected := map[string][]Edit{
+		StepRemoveCodeCommentIndicators:  {{Begins: 0, Ends: 1, Replaced: "//", Replacement: " "}},
+		StepReplaceCopyrightSymbols:      {{Begins: 3, Ends: 5, Replaced: "(c)", Replacement: "copyright"}},
+		StepReplaceWhitespace:            {{Begins: 0, Ends: 2, Replaced: "  ", Replacement: " "}, {Begins: 0, Ends: 0, Replaced: " ", Replacement: ""}},
+		StepReplaceVarietalWordSpellings: {{Begins: 7, Ends: 13, Replaced: "licence", Replacement: "license"}},
+	}
+	if d := cmp.Diff(expected, edits); d != "" {
+		t.Errorf("Didn't get expected edits: (-want, +got): %s", d)
+	}
+	last := n.Trace.Steps[len(n.Trace.Steps)-1]
+	if d := cmp.Diff(n.NormalizedText, last.NormalizedText); d != "" {
+		t.Errorf("Didn't get expected text after the last step: (-want, +got): %s", d)
+	}
+}
+
+func stepNames(steps []StepTrace) []string {
+	var names []string
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestReplaceVarietalWordSpellingsStep(t *testing.T) {
+	t.Parallel()
+	words, err := DefaultReplacementWords()
+	if err != nil {
+		t.Fatalf("DefaultReplacementWords() error = %v", err)
+	}
+	if _, err := ParseReplacementWords([]byte(`{"team": "crew("}`)); err == nil {
+		t.Errorf("ParseReplacementWords() expected an error for an invalid pattern")
+	}
+	more, err := ParseReplacementWords([]byte(`{"team": "crew"}`))
+	if err != nil {
+		t.Fatalf("ParseReplacementWords() error = %v", err)
+	}
+	step, err := ReplaceVarietalWordSpellingsStep(words.Merge(more))
+	if err != nil {
+		t.Fatalf("ReplaceVarietalWordSpellingsStep() error = %v", err)
+	}
+	pipeline, err := DefaultPipeline().Replace(step)
+	if err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if _, err := DefaultPipeline().Replace(Step{Name: "noSuchStep"}); err == nil {
+		t.Errorf("Replace() expected an error for an unknown step")
+	}
+
+	n := NormalizationData{OriginalText: "Crew licence"}
+	if err := pipeline.Normalize(&n); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if d := cmp.Diff("team license", n.NormalizedText); d != "" {
+		t.Errorf("Didn't get expected Normalized text: %s", fmt.Sprintf("(-want, +got): %s", d))
+	}
+}
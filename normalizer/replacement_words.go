@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+//go:embed replacement_words.json
+var replacementWordsBytes []byte
+
+// ReplacementWords are the varietal word spellings (Guideline 8.1.1).
+// Each key is a replacement word, and its value is the regexp pattern of the words which are replaced by it,
+// e.g. "license": "licence".
+type ReplacementWords map[string]string
+
+type replacementWord struct {
+	replacement string
+	re          *regexp.Regexp
+}
+
+var (
+	defaultReplacementWordsOnce sync.Once
+	defaultReplacementWords     []replacementWord
+	defaultReplacementWordsErr  error
+)
+
+// ParseReplacementWords reads replacement words in the JSON format of replacement_words.json and validates the patterns
+func ParseReplacementWords(b []byte) (ReplacementWords, error) {
+	words := make(ReplacementWords)
+	if err := json.Unmarshal(b, &words); err != nil {
+		return nil, err
+	}
+	if _, err := words.compile(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// DefaultReplacementWords returns the built-in replacement words
+func DefaultReplacementWords() (ReplacementWords, error) {
+	return ParseReplacementWords(replacementWordsBytes)
+}
+
+// Merge returns the words with the other words added. The pattern of a word in other replaces the pattern of the same word.
+func (w ReplacementWords) Merge(other ReplacementWords) ReplacementWords {
+	merged := make(ReplacementWords, len(w)+len(other))
+	for replacement, pattern := range w {
+		merged[replacement] = pattern
+	}
+	for replacement, pattern := range other {
+		merged[replacement] = pattern
+	}
+	return merged
+}
+
+// compile compiles the patterns, in the order of the replacement words so that the replacements are repeatable
+func (w ReplacementWords) compile() ([]replacementWord, error) {
+	compiled := make([]replacementWord, 0, len(w))
+	for replacement, pattern := range w {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for replacement word %q: %w", replacement, err)
+		}
+		compiled = append(compiled, replacementWord{replacement: replacement, re: re})
+	}
+	sort.Slice(compiled, func(i, j int) bool { return compiled[i].replacement < compiled[j].replacement })
+	return compiled, nil
+}
+
+// ReplaceVarietalWordSpellingsStep returns a replaceVarietalWordSpellings step which uses the words instead of the
+// built-in words (e.g. the built-in words merged with more words). Use Pipeline.Replace to replace the default step.
+func ReplaceVarietalWordSpellingsStep(words ReplacementWords) (Step, error) {
+	compiled, err := words.compile()
+	if err != nil {
+		return Step{}, err
+	}
+	return Step{Name: StepReplaceVarietalWordSpellings, Apply: func(n *NormalizationData) error {
+		n.replaceWords(compiled)
+		return nil
+	}}, nil
+}
+
+// replaceVarietalWordSpellings will read replacement words JSON file and replace matches
+// to create a consistent representation of words with alternate spellings.
+// The replacement may be a word or regexp pattern.
+// The file is only read once.
+// The patterns are only compiled once.
+// The compiled regexp are kept for reuse.
+func (n *NormalizationData) replaceVarietalWordSpellings() error {
+	defaultReplacementWordsOnce.Do(func() {
+		words, err := DefaultReplacementWords()
+		if err == nil {
+			defaultReplacementWords, err = words.compile()
+		}
+		if err != nil {
+			defaultReplacementWordsErr = fmt.Errorf("invalid built-in replacement words: %w", err)
+		}
+	})
+	if defaultReplacementWordsErr != nil {
+		return defaultReplacementWordsErr
+	}
+	n.replaceWords(defaultReplacementWords)
+	return nil
+}
+
+func (n *NormalizationData) replaceWords(words []replacementWord) {
+	for _, w := range words {
+		n.RegexpReplacePatternAndUpdateIndexMap(w.re, w.replacement)
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+// StepInitialize is the name of the first step in a trace, which folds the original text to NFKC lower case
+const StepInitialize = "initialize"
+
+// Trace records the effect of each normalization step.
+// Set NormalizationData.Trace to a new Trace before normalizing to record the steps.
+type Trace struct {
+	Steps []StepTrace
+	edits []Edit
+}
+
+// StepTrace is the normalized text after a step, and the replacements made by the step
+type StepTrace struct {
+	Name           string
+	NormalizedText string
+	// Edits are the replacements made with the replace methods (a custom step may also change the text directly)
+	Edits []Edit
+}
+
+// Edit is a replacement made by a normalization step
+type Edit struct {
+	// Begins and Ends are the indexes of the first and last replaced chars in the original text (-1 if not known)
+	Begins int
+	Ends   int
+	// Replaced is the normalized text which was replaced by the Replacement (an empty Replacement is a removal)
+	Replaced    string
+	Replacement string
+}
+
+// edit records a replacement of normalizedText[from:to] before the index map is updated
+func (t *Trace) edit(n *NormalizationData, from int, to int, replacement string) {
+	if to > len(n.NormalizedText) {
+		to = len(n.NormalizedText)
+	}
+	if from > to {
+		return
+	}
+	replaced := n.NormalizedText[from:to]
+	if replaced == replacement {
+		return
+	}
+	e := Edit{Begins: -1, Ends: -1, Replaced: replaced, Replacement: replacement}
+	if from < len(n.IndexMap) {
+		e.Begins = n.IndexMap[from]
+	}
+	if to > from && to-1 < len(n.IndexMap) {
+		e.Ends = n.IndexMap[to-1]
+	}
+	t.edits = append(t.edits, e)
+}
+
+// step records the normalized text and the edits after a step
+func (t *Trace) step(name string, n *NormalizationData) {
+	t.Steps = append(t.Steps, StepTrace{Name: name, NormalizedText: n.NormalizedText, Edits: t.edits})
+	t.edits = nil
+}
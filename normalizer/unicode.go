@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ignorableRunes are removed from the text: zero-width and invisible formatting characters (which PDF extraction and
+// some editors insert within words) and the trademark sign (which NFKC would otherwise expand to "tm")
+var ignorableRunes = map[rune]bool{
+	'\u00AD': true, // soft hyphen
+	'\u180E': true, // mongolian vowel separator
+	'\u200B': true, // zero width space
+	'\u200C': true, // zero width non-joiner
+	'\u200D': true, // zero width joiner
+	'\u200E': true, // left-to-right mark
+	'\u200F': true, // right-to-left mark
+	'\u2060': true, // word joiner
+	'\u2122': true, // trademark sign
+	'\uFEFF': true, // zero width no-break space (BOM)
+}
+
+// foldText returns the NFKC normalized, lower case text, without ignorable characters, and its index map.
+// NFKC folds compatibility characters such as full-width forms (Ａ to a), ligatures (ﬁ to fi) and
+// non-breaking spaces. Each index in the map is an index in the original text:
+// * Unchanged characters map to themselves, byte for byte.
+// * A changed character (or character sequence) maps its first byte to the first byte of the original,
+// its last byte to the last byte of the original, and any bytes in between to -1 (like a replacement).
+func foldText(text string) (string, []int) {
+	var b strings.Builder
+	b.Grow(len(text))
+	indexMap := make([]int, 0, len(text))
+
+	var iter norm.Iter
+	var segment []byte
+	iter.InitString(norm.NFKC, text)
+	for start := 0; !iter.Done(); {
+		segment = append(segment, iter.Next()...)
+		end := iter.Pos()
+		if end == start && !iter.Done() {
+			continue // the decomposition of a character may be returned in more than one part
+		}
+		original := text[start:end]
+
+		if isASCII(segment) && string(segment) == original {
+			// lower case ASCII has the same length
+			for i, c := range segment {
+				if 'A' <= c && c <= 'Z' {
+					c += 'a' - 'A'
+				}
+				_ = b.WriteByte(c)
+				indexMap = append(indexMap, start+i)
+			}
+			segment = segment[:0]
+			start = end
+			continue
+		}
+
+		folded := string(segment)
+		switch {
+		case strings.IndexFunc(original, isIgnorable) >= 0:
+			// Remove ignorable characters before NFKC (e.g. NFKC would expand ™ to TM)
+			folded = norm.NFKC.String(strings.Map(removeIgnorable, original))
+		case len(folded) > 1 && folded[0] == ' ' && original[0] != ' ':
+			// Keep spacing marks such as the acute accent (´), which NFKC decomposes to a space and a combining mark
+			folded = original
+		}
+		folded = strings.ToLower(folded)
+
+		switch {
+		case folded == original:
+			for i := start; i < end; i++ {
+				indexMap = append(indexMap, i)
+			}
+		case len(folded) > 0:
+			first := len(indexMap)
+			for i := 0; i < len(folded); i++ {
+				indexMap = append(indexMap, -1)
+			}
+			indexMap[first] = start
+			if len(folded) > 1 {
+				indexMap[len(indexMap)-1] = end - 1
+			}
+		}
+		b.WriteString(folded)
+		segment = segment[:0]
+		start = end
+	}
+	return b.String(), indexMap
+}
+
+func isIgnorable(r rune) bool {
+	return ignorableRunes[r]
+}
+
+func removeIgnorable(r rune) rune {
+	if ignorableRunes[r] {
+		return -1
+	}
+	return r
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package normalizer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_foldText(t *testing.T) {
+	t.Parallel()
+	tcs := []struct {
+		name             string
+		text             string
+		expectedText     string
+		expectedIndexMap []int
+	}{
+		{
+			name:             "ascii",
+			text:             "MIT License",
+			expectedText:     "mit license",
+			expectedIndexMap: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name:             "full-width",
+			text:             "ＭＩＴ",
+			expectedText:     "mit",
+			expectedIndexMap: []int{0, 3, 6},
+		},
+		{
+			name:             "ligature",
+			text:             "ﬁle",
+			expectedText:     "file",
+			expectedIndexMap: []int{0, 2, 3, 4},
+		},
+		{
+			name:             "zero-width",
+			text:             "soft\u200Bware\uFEFF",
+			expectedText:     "software",
+			expectedIndexMap: []int{0, 1, 2, 3, 7, 8, 9, 10},
+		},
+		{
+			name:             "non-breaking space",
+			text:             "a\u00A0b",
+			expectedText:     "a b",
+			expectedIndexMap: []int{0, 1, 3},
+		},
+		{
+			name:             "lower case changes length",
+			text:             "İ Été",
+			expectedText:     "i été",
+			expectedIndexMap: []int{0, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:             "ligature with the same length",
+			text:             "ĳ",
+			expectedText:     "ij",
+			expectedIndexMap: []int{0, 1},
+		},
+		{
+			name:             "combining accent is composed",
+			text:             "e\u0301",
+			expectedText:     "é",
+			expectedIndexMap: []int{0, 2},
+		},
+		{
+			name:             "spacing accent and trademark",
+			text:             "´quoted´ Name™",
+			expectedText:     "´quoted´ name",
+			expectedIndexMap: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			text, indexMap := foldText(tc.text)
+			if d := cmp.Diff(tc.expectedText, text); d != "" {
+				t.Errorf("Didn't get expected text: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+			if d := cmp.Diff(tc.expectedIndexMap, indexMap); d != "" {
+				t.Errorf("Didn't get expected IndexMap: %s", fmt.Sprintf("(-want, +got): %s", d))
+			}
+		})
+	}
+}
+
+func TestNormalizationData_NormalizeText_Unicode(t *testing.T) {
+	t.Parallel()
+	n := NormalizationData{OriginalText: "Ｐｅｒｍｉｓｓｉｏｎ  is hereby granted,\u00A0free of charge, to any person obtaining a co\u200Bpy of this ﬁle"}
+	if err := n.NormalizeText(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "permission is hereby granted,free of charge,to any person obtaining a copy of this file"
+	if d := cmp.Diff(expected, n.NormalizedText); d != "" {
+		t.Errorf("Didn't get expected Normalized text: %s", fmt.Sprintf("(-want, +got): %s", d))
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// overlayFS combines resource trees. Files in later layers replace files with the same name in earlier layers.
+type overlayFS []fs.FS
+
+// Overlay returns an fs.FS which combines the layers. Files in later layers take priority,
+// and directories list the combined entries of all the layers.
+func Overlay(layers ...fs.FS) fs.FS {
+	return overlayFS(layers)
+}
+
+// Open opens the named file from the last layer which has it
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for i := len(o) - 1; i >= 0; i-- {
+		f, err := o[i].Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir reads the named directory from every layer which has it and returns the combined entries sorted by name
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	found := false
+	entries := make(map[string]fs.DirEntry)
+	for _, layer := range o {
+		des, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, de := range des {
+			entries[de.Name()] = de
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	ret := make([]fs.DirEntry, 0, len(entries))
+	for _, de := range entries {
+		ret = append(ret, de)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name() < ret[j].Name() })
+	return ret, nil
+}
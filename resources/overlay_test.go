@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package resources
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOverlay(t *testing.T) {
+	t.Parallel()
+
+	base := fstest.MapFS{
+		"custom/default/license_patterns/MIT/license_MIT.txt": {Data: []byte("base MIT")},
+		"custom/default/license_patterns/ISC/license_ISC.txt": {Data: []byte("base ISC")},
+	}
+	top := fstest.MapFS{
+		"custom/default/license_patterns/MIT/license_MIT.txt":   {Data: []byte("top MIT")},
+		"custom/default/license_patterns/Team/license_Team.txt": {Data: []byte("top Team")},
+	}
+	o := Overlay(base, top)
+
+	files := map[string]string{
+		"custom/default/license_patterns/MIT/license_MIT.txt":   "top MIT",
+		"custom/default/license_patterns/ISC/license_ISC.txt":   "base ISC",
+		"custom/default/license_patterns/Team/license_Team.txt": "top Team",
+	}
+	for name, want := range files {
+		b, err := fs.ReadFile(o, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%v) error = %v", name, err)
+		}
+		if string(b) != want {
+			t.Errorf("ReadFile(%v) = %q, want %q", name, b, want)
+		}
+	}
+
+	des, err := fs.ReadDir(o, "custom/default/license_patterns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, de := range des {
+		got = append(got, de.Name())
+	}
+	if d := cmp.Diff([]string{"ISC", "MIT", "Team"}, got); d != "" {
+		t.Errorf("Didn't get expected entries: (-want, +got): %v", d)
+	}
+
+	if _, err := fs.ReadFile(o, "custom/default/license_patterns/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := fs.ReadDir(o, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
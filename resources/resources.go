@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package resources provides the license resources. The default resources are embedded in the binary,
+// so a scanner which is installed or copied without the source tree can still identify licenses.
+package resources
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+//go:embed spdx/default/json spdx/default/template spdx/default/precheck custom/default compatibility/default
+var embedded embed.FS
+
+// Embedded returns the default resources which are embedded in the binary
+func Embedded() fs.FS {
+	return embedded
+}
+
+// FS returns the resources directory as an fs.FS or, when no directory is configured, the embedded resources
+func FS(resourcesPath string) fs.FS {
+	if resourcesPath == "" {
+		return Embedded()
+	}
+	return os.DirFS(resourcesPath)
+}